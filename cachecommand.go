@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+)
+
+func cacheMain(args []string) int {
+	flagSet := flag.NewFlagSet("cache", flag.ExitOnError)
+	var reportFlags reportFlags
+	reportFlags.register(flagSet)
+	flagSet.Usage = func() {
+		fmt.Println("Usage: pjsekai-overlay cache [--json] [list / clear / path]")
+		flagSet.PrintDefaults()
+	}
+	flagSet.Parse(args)
+	reportFlags.apply()
+
+	cache := openCache()
+	switch flagSet.Arg(0) {
+	case "list":
+		entries, err := cache.List()
+		if err != nil {
+			return fail(stageCache, err)
+		}
+		report.cacheEntries(entries)
+	case "clear":
+		if err := cache.Clear(); err != nil {
+			return fail(stageCache, err)
+		}
+		report.cacheCleared(cache.Dir)
+	case "path":
+		report.info(cache.Dir)
+		report.output("cacheDir", cache.Dir)
+	default:
+		flagSet.Usage()
+		return 2
+	}
+
+	return 0
+}
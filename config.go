@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"sort"
+
+	"github.com/sevenc-nanashi/pjsekai-overlay/pkg/pjsekaioverlay"
+)
+
+// 設定ファイルに関するオプション。
+type configFlags struct {
+	profile  string
+	noConfig bool
+}
+
+func (flags *configFlags) register(flagSet *flag.FlagSet) {
+	flagSet.StringVar(&flags.profile, "profile", "", t("flag.profile"))
+	flagSet.BoolVar(&flags.noConfig, "no-config", false, t("flag.noConfig"))
+}
+
+// flagSet.Parseの後に呼ぶ。コマンドラインで指定されていないオプションに設定ファイルの値を入れる。
+// このコマンドに無いオプションは無視する。
+func (flags *configFlags) apply(flagSet *flag.FlagSet) error {
+	if flags.noConfig {
+		return nil
+	}
+	config, err := pjsekaioverlay.LoadConfig(pjsekaioverlay.ConfigPaths()...)
+	if err != nil {
+		return err
+	}
+	options, err := config.ResolveOptions(flags.profile)
+	if err != nil {
+		return err
+	}
+
+	specified := specifiedFlags(flagSet)
+	names := make([]string, 0, len(options))
+	for name := range options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if specified[name] || flagSet.Lookup(name) == nil {
+			continue
+		}
+		if err := flagSet.Set(name, options[name]); err != nil {
+			return pjsekaioverlay.Errorf("error.configOption", name, err)
+		}
+	}
+	pjsekaioverlay.RegisterSources(config.Sources)
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/fatih/color"
 	"github.com/lithammer/dedent"
 	"github.com/sevenc-nanashi/pjsekai-overlay/pkg/pjsekaioverlay"
 )
@@ -27,6 +28,20 @@ func Title() {
 
 }
 
+func PrintScoreBreakdown(scoreData pjsekaioverlay.ScoreResult) {
+	breakdown := scoreData.Breakdown
+	fmt.Print(t("breakdown.base", color.CyanString("%8d", breakdown.Base)))
+	fmt.Print(t("breakdown.level", color.CyanString("%+8d", breakdown.Level), breakdown.LevelFax))
+	fmt.Print(t("breakdown.combo", color.CyanString("%+8d", breakdown.Combo), breakdown.MaxComboFax))
+	fmt.Print(t("breakdown.skill", color.CyanString("%+8d", breakdown.Skill), breakdown.SkillFax))
+	fmt.Print(t("breakdown.total", color.MagentaString("%8d", breakdown.Total)))
+
+	finalScore := scoreData.Frames[len(scoreData.Frames)-1].Score
+	if finalScore != breakdown.Total {
+		fmt.Println(color.RedString(t("breakdown.mismatch", finalScore)))
+	}
+}
+
 func RgbColorEscape(rgb int) string {
 	return fmt.Sprintf("\033[38;2;%d;%d;%dm", (rgb>>16)&0xff, (rgb>>8)&0xff, rgb&0xff)
 }
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sevenc-nanashi/pjsekai-overlay/pkg/pjsekaioverlay"
+)
+
+type doctorReporter struct {
+	failed bool
+}
+
+func (reporter *doctorReporter) ok(name string, message string) {
+	report.check("ok", name, message)
+}
+
+func (reporter *doctorReporter) warn(name string, message string) {
+	report.check("warn", name, message)
+}
+
+func (reporter *doctorReporter) fail(name string, message string) {
+	reporter.failed = true
+	report.check("fail", name, message)
+}
+
+// 動作に必要な環境が揃っているかを確認する。
+func doctorMain(args []string) int {
+	flagSet := flag.NewFlagSet("doctor", flag.ExitOnError)
+
+	var offline bool
+	flagSet.BoolVar(&offline, "offline", false, t("flag.offline"))
+
+	var reportFlags reportFlags
+	reportFlags.register(flagSet)
+
+	flagSet.Usage = func() {
+		fmt.Println(t("usage.doctor"))
+		flagSet.PrintDefaults()
+	}
+	flagSet.Parse(args)
+	reportFlags.apply()
+
+	reporter := &doctorReporter{}
+	report.info(fmt.Sprintf("pjsekai-overlay %s", pjsekaioverlay.Version))
+
+	assets, err := executableAssets()
+	if err != nil {
+		reporter.fail(t("doctor.assets"), err.Error())
+	} else {
+		missing := []string{}
+		for _, asset := range pjsekaioverlay.REQUIRED_ASSETS {
+			if _, err := os.Stat(filepath.Join(assets, asset)); err != nil {
+				missing = append(missing, asset)
+			}
+		}
+		if len(missing) > 0 {
+			reporter.fail(t("doctor.assets"), t("doctor.missingAssets", assets, missing[0]))
+		} else {
+			reporter.ok(t("doctor.assets"), assets)
+		}
+
+		weightsPath := filepath.Join(filepath.Dir(assets), "weights.json")
+		if _, err := os.Stat(weightsPath); err == nil {
+			if _, err := pjsekaioverlay.LoadWeightConfig(weightsPath); err != nil {
+				reporter.fail(t("doctor.weights"), err.Error())
+			} else {
+				reporter.ok(t("doctor.weights"), weightsPath)
+			}
+		}
+	}
+
+	exeditRoot := pjsekaioverlay.FindExeditRoot()
+	if exeditRoot == "" {
+		reporter.warn("AviUtl", t("doctor.aviutlNotRunning"))
+	} else {
+		reporter.ok("AviUtl", exeditRoot)
+		installedVersion, err := pjsekaioverlay.InstalledObjectVersion(exeditRoot)
+		if err != nil {
+			reporter.fail(t("doctor.object"), t("doctor.objectNotInstalled"))
+		} else if installedVersion != pjsekaioverlay.Version {
+			reporter.fail(t("doctor.object"), t("doctor.objectOutdated", installedVersion))
+		} else {
+			reporter.ok(t("doctor.object"), installedVersion)
+		}
+	}
+
+	cache := openCache()
+	if err := os.MkdirAll(cache.Dir, 0755); err != nil {
+		reporter.fail(t("doctor.cache"), err.Error())
+	} else {
+		reporter.ok(t("doctor.cache"), cache.Dir)
+	}
+
+	if !offline {
+		client := &http.Client{Timeout: 10 * time.Second}
+		for _, source := range pjsekaioverlay.SOURCES {
+			resp, err := client.Get("https://" + source.Host + "/sonolus/info")
+			if err != nil {
+				reporter.fail(source.Name, t("error.connectCause", err))
+				continue
+			}
+			resp.Body.Close()
+			if resp.StatusCode != 200 {
+				reporter.fail(source.Name, t("doctor.serverError", resp.StatusCode))
+			} else {
+				reporter.ok(source.Name, source.Host)
+			}
+		}
+	}
+
+	if reporter.failed {
+		return 1
+	}
+	return 0
+}
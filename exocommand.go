@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+)
+
+// exoファイルだけを生成する。
+func exoMain(args []string) int {
+	flagSet := flag.NewFlagSet("exo", flag.ExitOnError)
+
+	var outDirFlags outDirFlags
+	outDirFlags.register(flagSet)
+
+	var assets string
+	flagSet.StringVar(&assets, "assets", "", t("flag.assets"))
+
+	var exoFlags exoFlags
+	exoFlags.register(flagSet)
+
+	var configFlags configFlags
+	configFlags.register(flagSet)
+
+	var reportFlags reportFlags
+	reportFlags.register(flagSet)
+
+	flagSet.Usage = func() {
+		fmt.Println(t("usage.exo"))
+		flagSet.PrintDefaults()
+	}
+	flagSet.Parse(args)
+	reportFlags.apply()
+	if err := configFlags.apply(flagSet); err != nil {
+		return fail(stageOptions, err)
+	}
+	reportFlags.apply()
+
+	chartId := flagSet.Arg(0)
+	if chartId == "" {
+		flagSet.Usage()
+		return 2
+	}
+
+	exoOptions, err := exoFlags.exoOptions()
+	if err != nil {
+		return fail(stageOptions, err)
+	}
+
+	chartSource, chart, err := fetchChart(openCache(), chartId)
+	if err != nil {
+		return exitCode(err)
+	}
+	formattedOutDir, err := resolveOutDir(outDirFlags, chartId, chartSource, chart, exoFlags.difficulty, false)
+	if err != nil {
+		return exitCode(err)
+	}
+	assets, err = findAssets(formattedOutDir, assets)
+	if err != nil {
+		return fail(stageAssets, err)
+	}
+	if err := writeExo(chartSource, chart, assets, formattedOutDir, exoOptions); err != nil {
+		return exitCode(err)
+	}
+
+	return 0
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+)
+
+// 譜面の情報、ジャケット、背景、譜面データを取得する。譜面データはキャッシュに保存される。
+func fetchMain(args []string) int {
+	flagSet := flag.NewFlagSet("fetch", flag.ExitOnError)
+
+	var outDirFlags outDirFlags
+	outDirFlags.register(flagSet)
+	outDirFlags.registerOnExists(flagSet)
+
+	var configFlags configFlags
+	configFlags.register(flagSet)
+
+	var reportFlags reportFlags
+	reportFlags.register(flagSet)
+
+	flagSet.Usage = func() {
+		fmt.Println(t("usage.fetch"))
+		flagSet.PrintDefaults()
+	}
+	flagSet.Parse(args)
+	reportFlags.apply()
+	if err := configFlags.apply(flagSet); err != nil {
+		return fail(stageOptions, err)
+	}
+	reportFlags.apply()
+	if err := outDirFlags.validate(); err != nil {
+		return fail(stageOptions, err)
+	}
+
+	chartId := flagSet.Arg(0)
+	if chartId == "" {
+		flagSet.Usage()
+		return 2
+	}
+
+	cache := openCache()
+	chartSource, chart, err := fetchChart(cache, chartId)
+	if err != nil {
+		return exitCode(err)
+	}
+	formattedOutDir, err := resolveOutDir(outDirFlags, chartId, chartSource, chart, "", report.interactive() && stdinIsTerminal())
+	if err != nil {
+		return exitCode(err)
+	}
+	if err := downloadResources(chartSource, chart, formattedOutDir); err != nil {
+		return exitCode(err)
+	}
+	if _, err := loadLevelData(cache, chartSource, chart); err != nil {
+		return exitCode(err)
+	}
+
+	return 0
+}
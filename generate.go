@@ -0,0 +1,172 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/sevenc-nanashi/pjsekai-overlay/pkg/pjsekaioverlay"
+	"github.com/sevenc-nanashi/pjsekai-overlay/pkg/sonolus"
+)
+
+// 譜面の取得からexoファイルの生成までを一度に行う。
+func generateMain(args []string) int {
+	flagSet := flag.NewFlagSet("generate", flag.ExitOnError)
+
+	var skipAviutlInstall bool
+	flagSet.BoolVar(&skipAviutlInstall, "no-aviutl-install", false, t("flag.noAviutlInstall"))
+
+	var outDirFlags outDirFlags
+	outDirFlags.register(flagSet)
+	outDirFlags.registerOnExists(flagSet)
+
+	var scoreFlags scoreFlags
+	scoreFlags.register(flagSet)
+
+	var pedFlags pedFlags
+	pedFlags.register(flagSet)
+
+	var extraOutputFlags extraOutputFlags
+	extraOutputFlags.register(flagSet)
+
+	var showScoreBreakdown bool
+	flagSet.BoolVar(&showScoreBreakdown, "score-breakdown", false, t("flag.scoreBreakdown"))
+
+	var exoFlags exoFlags
+	exoFlags.register(flagSet)
+
+	var inputFlags inputFlags
+	inputFlags.register(flagSet)
+
+	var configFlags configFlags
+	configFlags.register(flagSet)
+
+	var updateFlags updateFlags
+	updateFlags.register(flagSet)
+
+	var reportFlags reportFlags
+	reportFlags.register(flagSet)
+
+	flagSet.Usage = func() {
+		fmt.Println(t("usage.generate"))
+		flagSet.PrintDefaults()
+	}
+
+	flagSet.Parse(args)
+	// 設定ファイルの読み込みに失敗した場合もJSONで出力できるよう、先に一度適用する
+	reportFlags.apply()
+	if err := configFlags.apply(flagSet); err != nil {
+		return fail(stageOptions, err)
+	}
+	reportFlags.apply()
+	if err := outDirFlags.validate(); err != nil {
+		return fail(stageOptions, err)
+	}
+	inputFlags.parsed(flagSet)
+
+	if report.interactive() {
+		Title()
+	}
+
+	skills, err := scoreFlags.skills()
+	if err != nil {
+		return fail(stageOptions, err)
+	}
+	judgeTable, err := scoreFlags.judgeTable()
+	if err != nil {
+		return fail(stageOptions, err)
+	}
+	pedOptions, err := pedFlags.pedOptions(skills, judgeTable.Life)
+	if err != nil {
+		return fail(stageOptions, err)
+	}
+	exoOptions, err := exoFlags.exoOptions()
+	if err != nil {
+		return fail(stageOptions, err)
+	}
+
+	checkUpdate(&updateFlags)
+
+	if !skipAviutlInstall {
+		success := pjsekaioverlay.TryInstallObject()
+		if success {
+			report.info(t("generate.objectInstalled"))
+		}
+	}
+
+	var chartId string
+	if flagSet.Arg(0) != "" {
+		chartId = flagSet.Arg(0)
+		report.info(t("common.chartId", color.GreenString(chartId)))
+	} else if inputFlags.canAsk() {
+		chartId = promptString(t("prompt.chartId"))
+	} else {
+		return fail(stageOptions, withCode("noChartId", pjsekaioverlay.Errorf("error.noChartId", errNoInput())))
+	}
+
+	cache := openCache()
+	chartSource, chart, err := fetchChart(cache, chartId)
+	if err != nil {
+		return exitCode(err)
+	}
+
+	formattedOutDir, err := resolveOutDir(outDirFlags, chartId, chartSource, chart, exoFlags.difficulty, !inputFlags.yes && inputFlags.canAsk())
+	if err != nil {
+		return exitCode(err)
+	}
+
+	if err := downloadResources(chartSource, chart, formattedOutDir); err != nil {
+		return exitCode(err)
+	}
+
+	levelData, err := loadLevelData(cache, chartSource, chart)
+	if err != nil {
+		return exitCode(err)
+	}
+
+	if inputFlags.shouldAsk("team-power") {
+		scoreFlags.teamPower, err = promptInt(t("prompt.teamPower"))
+		if err != nil {
+			return fail(stageOptions, err)
+		}
+	}
+
+	scoreData, err := calculateScore(chart, levelData, &scoreFlags, skills, judgeTable)
+	if err != nil {
+		return exitCode(err)
+	}
+
+	if showScoreBreakdown {
+		report.score(scoreData, "")
+	}
+
+	if inputFlags.shouldAsk("ap-combo") {
+		// マニフェストに書けるよう、pedFlagsにも入れる
+		pedFlags.apCombo = promptYesNo(t("prompt.apCombo"), true)
+		pedOptions.Ap = pedFlags.apCombo
+	}
+
+	assets, pedAssets, err := resolveAssets(formattedOutDir, pedFlags.portable)
+	if err != nil {
+		return exitCode(err)
+	}
+	pedOptions.Assets = pedAssets
+	pedOptions.LevelInfo = sonolus.LevelInfo{Rating: chart.Rating}
+
+	if err := writePed(scoreData, formattedOutDir, pedOptions); err != nil {
+		return exitCode(err)
+	}
+
+	if err := writeExtraOutputs(scoreData, chart, formattedOutDir, pedOptions, extraOutputFlags); err != nil {
+		return exitCode(err)
+	}
+	if err := writeExo(chartSource, chart, assets, formattedOutDir, exoOptions); err != nil {
+		return exitCode(err)
+	}
+	if err := writeManifest(chartId, chartSource, chart, manifestOptions(flagSet, nil), formattedOutDir); err != nil {
+		return exitCode(err)
+	}
+
+	report.info(color.GreenString(t("generate.done")))
+	return 0
+}
@@ -5,6 +5,7 @@ go 1.20
 replace github.com/sevenc-nanashi/pjsekai-overlay => ./
 
 require (
+	github.com/google/go-github/v57 v57.0.0
 	github.com/lithammer/dedent v1.1.0
 	golang.org/x/text v0.8.0
 )
@@ -13,7 +14,6 @@ require (
 	github.com/go-ole/go-ole v1.2.6 // indirect
 	github.com/google/cabbie v1.0.2 // indirect
 	github.com/google/glazier v0.0.0-20211029225403-9f766cca891d // indirect
-	github.com/google/go-github/v57 v57.0.0 // indirect
 	github.com/google/go-querystring v1.1.0 // indirect
 	github.com/scjalliance/comshim v0.0.0-20190308082608-cf06d2532c4e // indirect
 )
@@ -22,7 +22,7 @@ require (
 	github.com/fatih/color v1.14.1
 	github.com/iamacarpet/go-win64api v0.0.0-20221230174906-cb41e6e774e8
 	github.com/mattn/go-colorable v0.1.13 // indirect
-	github.com/mattn/go-isatty v0.0.17 // indirect
+	github.com/mattn/go-isatty v0.0.17
 	github.com/srinathh/gokilo v0.0.0-20200224143053-07425ce1c9d2
 	golang.org/x/image v0.6.0
 	golang.org/x/sys v0.5.0
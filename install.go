@@ -0,0 +1,36 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/sevenc-nanashi/pjsekai-overlay/pkg/pjsekaioverlay"
+)
+
+// AviUtlオブジェクトだけをインストールする。
+func installMain(args []string) int {
+	flagSet := flag.NewFlagSet("install", flag.ExitOnError)
+	var reportFlags reportFlags
+	reportFlags.register(flagSet)
+
+	flagSet.Usage = func() {
+		fmt.Println("Usage: pjsekai-overlay install")
+		flagSet.PrintDefaults()
+	}
+	flagSet.Parse(args)
+	reportFlags.apply()
+
+	exeditRoot := pjsekaioverlay.FindExeditRoot()
+	if exeditRoot == "" {
+		return fail(stageInstallObject, withCode("aviutlNotFound", pjsekaioverlay.Errorf("install.aviutlNotFound")))
+	}
+	if pjsekaioverlay.TryInstallObject() {
+		report.info(color.GreenString(t("generate.objectInstalled")))
+	} else {
+		report.info(t("install.upToDate"))
+	}
+	report.output("object", pjsekaioverlay.ObjectPath(exeditRoot))
+
+	return 0
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/sevenc-nanashi/pjsekai-overlay/pkg/pjsekaioverlay"
+	"golang.org/x/sys/windows"
+)
+
+func t(key string, args ...any) string {
+	return pjsekaioverlay.T(key, args...)
+}
+
+// --langはどのコマンドでも使えるよう、各コマンドのflagSetで解析する前に取り除く。
+func extractLangFlag(args []string) (string, []string) {
+	lang := ""
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != "lang" {
+			rest = append(rest, arg)
+			continue
+		}
+		if hasValue {
+			lang = value
+		} else if i+1 < len(args) {
+			lang = args[i+1]
+			i++
+		}
+	}
+	return lang, rest
+}
+
+func hasFlag(args []string, name string) bool {
+	for _, arg := range args {
+		if arg == "--" {
+			return false
+		}
+		if flagName, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "="); strings.HasPrefix(arg, "-") && flagName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Windowsの表示言語。「ja-JP」など。
+func systemLocales() []string {
+	languages, err := windows.GetUserPreferredUILanguages(windows.MUI_LANGUAGE_NAME)
+	if err != nil {
+		return nil
+	}
+	return languages
+}
+
+// メッセージの言語を決め、--langを取り除いた引数を返す。
+// --lang、設定ファイルのlang、環境変数、Windowsの表示言語の順に、対応している最初の言語を使う。
+func setupLocale(args []string) []string {
+	lang, args := extractLangFlag(args)
+
+	locales := []string{lang}
+	if !hasFlag(args, "no-config") {
+		if config, err := pjsekaioverlay.LoadConfig(pjsekaioverlay.ConfigPaths()...); err == nil {
+			locales = append(locales, config.Lang)
+		}
+	}
+	locales = append(locales, pjsekaioverlay.LocalesFromEnv()...)
+	locales = append(locales, systemLocales()...)
+
+	for _, locale := range locales {
+		if locale != "" && pjsekaioverlay.SetLocale(locale) == nil {
+			break
+		}
+	}
+	if lang != "" && pjsekaioverlay.NormalizeLocale(lang) != pjsekaioverlay.Locale() {
+		fmt.Fprintln(os.Stderr, color.YellowString(t("warning.unknownLocale", lang)))
+	}
+
+	return args
+}
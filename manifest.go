@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/sevenc-nanashi/pjsekai-overlay/pkg/pjsekaioverlay"
+	"github.com/sevenc-nanashi/pjsekai-overlay/pkg/sonolus"
+)
+
+// マニフェストに書かないオプション。出力の内容に関係しないもの。
+var MANIFEST_EXCLUDED_OPTIONS = map[string]bool{
+	"json":              true,
+	"profile":           true,
+	"no-config":         true,
+	"yes":               true,
+	"no-input":          true,
+	"no-update-check":   true,
+	"no-aviutl-install": true,
+	"out-dir":           true,
+	"on-exists":         true,
+	"score-breakdown":   true,
+	"refresh":           true,
+}
+
+// ファイルのパスを取るオプション。別のディレクトリからregenしても使えるよう、絶対パスにして書く。
+var MANIFEST_PATH_OPTIONS = []string{"weights", "judgements", "judge-table", "rank-table"}
+
+// baseにflagSetの全てのオプションの値を上書きしたものを返す。
+func manifestOptions(flagSet *flag.FlagSet, base map[string]string) map[string]string {
+	options := map[string]string{}
+	for name, value := range base {
+		options[name] = value
+	}
+	flagSet.VisitAll(func(f *flag.Flag) {
+		if !MANIFEST_EXCLUDED_OPTIONS[f.Name] {
+			options[f.Name] = f.Value.String()
+		}
+	})
+	for _, name := range MANIFEST_PATH_OPTIONS {
+		// rank-tableは「solo」などの名前の場合もあるので、ファイルがある場合だけ変換する
+		if _, err := os.Stat(options[name]); options[name] == "" || err != nil {
+			continue
+		}
+		if path, err := filepath.Abs(options[name]); err == nil {
+			options[name] = path
+		}
+	}
+	return options
+}
+
+// コマンドラインで指定されていないオプションにマニフェストの値を入れる。このコマンドに無いオプションは無視する。
+func applyManifestOptions(flagSet *flag.FlagSet, manifest pjsekaioverlay.Manifest, specified map[string]bool) error {
+	names := make([]string, 0, len(manifest.Options))
+	for name := range manifest.Options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if specified[name] || flagSet.Lookup(name) == nil {
+			continue
+		}
+		if err := flagSet.Set(name, manifest.Options[name]); err != nil {
+			return pjsekaioverlay.Errorf("error.manifestOption", name, err)
+		}
+	}
+	return nil
+}
+
+func writeManifest(chartId string, chartSource pjsekaioverlay.Source, chart sonolus.LevelInfo, options map[string]string, outDir string) error {
+	report.stageStarted(stageManifest, t("progress.manifest"))
+	manifest := pjsekaioverlay.NewManifest(chartId, chartSource, chart, options)
+	if err := manifest.HashFiles(outDir); err != nil {
+		return failStage(stageManifest, err)
+	}
+	if err := pjsekaioverlay.WriteManifest(outDir, manifest); err != nil {
+		return failStage(stageManifest, err)
+	}
+
+	report.stageFinished(stageManifest)
+	report.output("manifest", filepath.Join(outDir, pjsekaioverlay.ManifestName))
+	return nil
+}
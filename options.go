@@ -0,0 +1,164 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/sevenc-nanashi/pjsekai-overlay/pkg/pjsekaioverlay"
+)
+
+// スコアの計算に使うオプション。generate、score、ped buildなどで共通。
+type scoreFlags struct {
+	teamPower      int
+	weightsPath    string
+	judgementsPath string
+	judgeTablePath string
+	skillsSpec     string
+	skillDuration  float64
+	skillBonus     float64
+}
+
+func (flags *scoreFlags) register(flagSet *flag.FlagSet) {
+	flagSet.IntVar(&flags.teamPower, "team-power", 250000, t("flag.teamPower"))
+	flagSet.StringVar(&flags.weightsPath, "weights", "", t("flag.weights"))
+	flagSet.StringVar(&flags.judgementsPath, "judgements", "", t("flag.judgements"))
+	flagSet.StringVar(&flags.judgeTablePath, "judge-table", "", t("flag.judgeTable"))
+	flagSet.StringVar(&flags.skillsSpec, "skills", "", t("flag.skills"))
+	flagSet.Float64Var(&flags.skillDuration, "skill-duration", 5, t("flag.skillDuration"))
+	flagSet.Float64Var(&flags.skillBonus, "skill-bonus", 100, t("flag.skillBonus"))
+}
+
+func (flags *scoreFlags) skills() ([]pjsekaioverlay.SkillWindow, error) {
+	return pjsekaioverlay.ParseSkillWindows(flags.skillsSpec, flags.skillDuration, flags.skillBonus/100)
+}
+
+// judgeTablePathが空の場合は既定のテーブルを返す。
+func (flags *scoreFlags) judgeTable() (pjsekaioverlay.JudgeTable, error) {
+	if flags.judgeTablePath == "" {
+		return pjsekaioverlay.DEFAULT_JUDGE_TABLE, nil
+	}
+	return pjsekaioverlay.LoadJudgeTable(flags.judgeTablePath)
+}
+
+// pedファイルの生成に使うオプション。
+type pedFlags struct {
+	apCombo       bool
+	rankTableName string
+	feverSpec     string
+	frameTableFps float64
+	portable      bool
+	deterministic bool
+	timestampSpec string
+}
+
+func (flags *pedFlags) register(flagSet *flag.FlagSet) {
+	flagSet.BoolVar(&flags.apCombo, "ap-combo", true, t("flag.apCombo"))
+	flagSet.StringVar(&flags.rankTableName, "rank-table", "solo", t("flag.rankTable"))
+	flagSet.StringVar(&flags.feverSpec, "fever", "", t("flag.fever"))
+	flagSet.Float64Var(&flags.frameTableFps, "frame-table-fps", 0, t("flag.frameTableFps"))
+	flagSet.BoolVar(&flags.portable, "portable", false, t("flag.portable"))
+	flagSet.BoolVar(&flags.deterministic, "deterministic", false, t("flag.deterministic"))
+	flagSet.StringVar(&flags.timestampSpec, "timestamp", "now", t("flag.timestamp"))
+}
+
+// LevelInfoとAssets以外を埋めたPedOptionsを返す。
+func (flags *pedFlags) pedOptions(skills []pjsekaioverlay.SkillWindow, life pjsekaioverlay.LifeTable) (pjsekaioverlay.PedOptions, error) {
+	rankTable, err := pjsekaioverlay.GetRankTable(flags.rankTableName)
+	if err != nil {
+		return pjsekaioverlay.PedOptions{}, err
+	}
+	var fever *pjsekaioverlay.TimeWindow
+	if flags.feverSpec != "" {
+		feverWindow, err := pjsekaioverlay.ParseTimeWindow(flags.feverSpec)
+		if err != nil {
+			return pjsekaioverlay.PedOptions{}, err
+		}
+		fever = &feverWindow
+	}
+	timestamp, err := pjsekaioverlay.ResolveTimestamp(flags.timestampSpec, flags.deterministic)
+	if err != nil {
+		return pjsekaioverlay.PedOptions{}, err
+	}
+
+	return pjsekaioverlay.PedOptions{
+		Ap:        flags.apCombo,
+		Life:      life,
+		RankTable: rankTable,
+		Skills:    skills,
+		Fever:     fever,
+
+		FrameTableFps: flags.frameTableFps,
+		Timestamp:     &timestamp,
+	}, nil
+}
+
+// タイムラインとグラフの出力に使うオプション。generateとregenで共通。
+type extraOutputFlags struct {
+	timelineFormats string
+	timelineFps     float64
+	graph           bool
+	graphDensity    bool
+}
+
+func (flags *extraOutputFlags) register(flagSet *flag.FlagSet) {
+	flagSet.StringVar(&flags.timelineFormats, "timeline", "", t("flag.timeline"))
+	flagSet.Float64Var(&flags.timelineFps, "timeline-fps", 60, t("flag.timelineFps"))
+	flagSet.BoolVar(&flags.graph, "graph", false, t("flag.graph"))
+	flagSet.BoolVar(&flags.graphDensity, "graph-density", false, t("flag.graphDensity"))
+}
+
+// exoファイルの生成に使うオプション。
+type exoFlags struct {
+	difficulty string
+	theme      string
+	resolution string
+}
+
+func (flags *exoFlags) register(flagSet *flag.FlagSet) {
+	flagSet.StringVar(&flags.difficulty, "difficulty", "MASTER", t("flag.difficulty"))
+	flagSet.StringVar(&flags.theme, "theme", "", t("flag.theme"))
+	flagSet.StringVar(&flags.resolution, "resolution", "", t("flag.resolution"))
+}
+
+func (flags *exoFlags) exoOptions() (pjsekaioverlay.ExoOptions, error) {
+	options := pjsekaioverlay.ExoOptions{Difficulty: flags.difficulty, Theme: flags.theme}
+	if _, ok := pjsekaioverlay.EXO_THEMES[flags.theme]; flags.theme != "" && !ok {
+		return options, pjsekaioverlay.Errorf("error.invalidTheme", flags.theme)
+	}
+	if flags.resolution != "" {
+		var err error
+		options.Width, options.Height, err = pjsekaioverlay.ParseResolution(flags.resolution)
+		if err != nil {
+			return options, err
+		}
+	}
+	return options, nil
+}
+
+// 出力先ディレクトリのオプション。
+type outDirFlags struct {
+	template string
+	// ask / overwrite / suffix。空の場合はoverwrite
+	onExists string
+}
+
+var ON_EXISTS_MODES = map[string]bool{
+	"ask":       true,
+	"overwrite": true,
+	"suffix":    true,
+}
+
+func (flags *outDirFlags) register(flagSet *flag.FlagSet) {
+	flagSet.StringVar(&flags.template, "out-dir", "./dist/_chartId_", t("flag.outDir"))
+}
+
+// 出力先ディレクトリを新しく作るコマンドだけで使う。exoなどは既にあるディレクトリに書き込むため使わない。
+func (flags *outDirFlags) registerOnExists(flagSet *flag.FlagSet) {
+	flagSet.StringVar(&flags.onExists, "on-exists", "ask", t("flag.onExists"))
+}
+
+func (flags *outDirFlags) validate() error {
+	if flags.onExists != "" && !ON_EXISTS_MODES[flags.onExists] {
+		return pjsekaioverlay.Errorf("error.invalidOnExists", flags.onExists)
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/sevenc-nanashi/pjsekai-overlay/pkg/pjsekaioverlay"
+	"github.com/sevenc-nanashi/pjsekai-overlay/pkg/sonolus"
+)
+
+func pedMain(args []string) int {
+	pedCommands := map[string]func(args []string) int{
+		"build":    pedBuildMain,
+		"inspect":  pedInspectMain,
+		"validate": pedValidateMain,
+		"diff":     pedDiffMain,
+	}
+	if len(args) == 0 {
+		fmt.Println("Usage: pjsekai-overlay ped [build / inspect / validate / diff] ...")
+		return 2
+	}
+	command, ok := pedCommands[args[0]]
+	if !ok {
+		fmt.Fprintln(os.Stderr, color.RedString(t("common.unknownCommand", "ped "+args[0])))
+		return 2
+	}
+	return command(args[1:])
+}
+
+// 譜面からpedファイルだけを生成する。
+func pedBuildMain(args []string) int {
+	flagSet := flag.NewFlagSet("ped build", flag.ExitOnError)
+
+	var outDirFlags outDirFlags
+	outDirFlags.register(flagSet)
+	// exoファイルは作らないが、出力先をgenerateと揃えるために使う
+	var difficulty string
+	flagSet.StringVar(&difficulty, "difficulty", "MASTER", t("flag.pedDifficulty"))
+
+	var scoreFlags scoreFlags
+	scoreFlags.register(flagSet)
+
+	var pedFlags pedFlags
+	pedFlags.register(flagSet)
+
+	var configFlags configFlags
+	configFlags.register(flagSet)
+
+	var reportFlags reportFlags
+	reportFlags.register(flagSet)
+
+	flagSet.Usage = func() {
+		fmt.Println(t("usage.pedBuild"))
+		flagSet.PrintDefaults()
+	}
+	flagSet.Parse(args)
+	reportFlags.apply()
+	if err := configFlags.apply(flagSet); err != nil {
+		return fail(stageOptions, err)
+	}
+	reportFlags.apply()
+
+	chartId := flagSet.Arg(0)
+	if chartId == "" {
+		flagSet.Usage()
+		return 2
+	}
+	skills, err := scoreFlags.skills()
+	if err != nil {
+		return fail(stageOptions, err)
+	}
+	judgeTable, err := scoreFlags.judgeTable()
+	if err != nil {
+		return fail(stageOptions, err)
+	}
+	pedOptions, err := pedFlags.pedOptions(skills, judgeTable.Life)
+	if err != nil {
+		return fail(stageOptions, err)
+	}
+
+	cache := openCache()
+	chartSource, chart, err := fetchChart(cache, chartId)
+	if err != nil {
+		return exitCode(err)
+	}
+	formattedOutDir, err := resolveOutDir(outDirFlags, chartId, chartSource, chart, difficulty, false)
+	if err != nil {
+		return exitCode(err)
+	}
+	levelData, err := loadLevelData(cache, chartSource, chart)
+	if err != nil {
+		return exitCode(err)
+	}
+	scoreData, err := calculateScore(chart, levelData, &scoreFlags, skills, judgeTable)
+	if err != nil {
+		return exitCode(err)
+	}
+	_, pedAssets, err := resolveAssets(formattedOutDir, pedFlags.portable)
+	if err != nil {
+		return exitCode(err)
+	}
+	pedOptions.Assets = pedAssets
+	pedOptions.LevelInfo = sonolus.LevelInfo{Rating: chart.Rating}
+	if err := writePed(scoreData, formattedOutDir, pedOptions); err != nil {
+		return exitCode(err)
+	}
+
+	return 0
+}
+
+func pedInspectMain(args []string) int {
+	flagSet := flag.NewFlagSet("ped inspect", flag.ExitOnError)
+	var eventCount int
+	flagSet.IntVar(&eventCount, "events", 5, t("flag.events"))
+	var reportFlags reportFlags
+	reportFlags.register(flagSet)
+	flagSet.Usage = func() {
+		fmt.Println(t("usage.pedInspect"))
+		flagSet.PrintDefaults()
+	}
+	flagSet.Parse(args)
+	reportFlags.apply()
+	if flagSet.NArg() != 1 {
+		flagSet.Usage()
+		return 2
+	}
+
+	pedFile, err := pjsekaioverlay.ParsePedFile(flagSet.Arg(0))
+	if err != nil {
+		return fail(stagePed, err)
+	}
+	report.pedSummary(pjsekaioverlay.SummarizePed(pedFile, eventCount))
+
+	return 0
+}
+
+func pedValidateMain(args []string) int {
+	flagSet := flag.NewFlagSet("ped validate", flag.ExitOnError)
+	var rating int
+	flagSet.IntVar(&rating, "rating", 0, t("flag.validateRating"))
+	var rankTableName string
+	flagSet.StringVar(&rankTableName, "rank-table", "solo", t("flag.rankTable"))
+	var reportFlags reportFlags
+	reportFlags.register(flagSet)
+	flagSet.Usage = func() {
+		fmt.Println(t("usage.pedValidate"))
+		flagSet.PrintDefaults()
+	}
+	flagSet.Parse(args)
+	reportFlags.apply()
+	if flagSet.NArg() != 1 {
+		flagSet.Usage()
+		return 2
+	}
+
+	var rankTable pjsekaioverlay.RankTable
+	if rating > 0 {
+		var err error
+		rankTable, err = pjsekaioverlay.GetRankTable(rankTableName)
+		if err != nil {
+			return fail(stageOptions, err)
+		}
+	}
+	pedFile, err := pjsekaioverlay.ParsePedFile(flagSet.Arg(0))
+	if err != nil {
+		return fail(stagePed, err)
+	}
+	problems := pjsekaioverlay.ValidatePed(pedFile)
+	if rating > 0 {
+		problems = append(problems, pjsekaioverlay.ValidatePedRanks(pedFile, rankTable, rating)...)
+	}
+	report.pedProblems(problems)
+	if len(problems) > 0 {
+		return 1
+	}
+
+	return 0
+}
+
+func pedDiffMain(args []string) int {
+	flagSet := flag.NewFlagSet("ped diff", flag.ExitOnError)
+	var reportFlags reportFlags
+	reportFlags.register(flagSet)
+	flagSet.Usage = func() {
+		fmt.Println(t("usage.pedDiff"))
+		flagSet.PrintDefaults()
+	}
+	flagSet.Parse(args)
+	reportFlags.apply()
+	if flagSet.NArg() != 2 {
+		flagSet.Usage()
+		return 2
+	}
+
+	left, err := pjsekaioverlay.ParsePedFile(flagSet.Arg(0))
+	if err != nil {
+		return fail(stagePed, err)
+	}
+	right, err := pjsekaioverlay.ParsePedFile(flagSet.Arg(1))
+	if err != nil {
+		return fail(stagePed, err)
+	}
+
+	differences := pjsekaioverlay.DiffPed(left, right)
+	report.pedDifferences(differences)
+	if len(differences) > 0 {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/sevenc-nanashi/pjsekai-overlay/pkg/pjsekaioverlay"
+	"github.com/sevenc-nanashi/pjsekai-overlay/pkg/sonolus"
+)
+
+// 各コマンドで共通の処理。進捗と失敗はreportで出力するので、呼び出し元はexitCode(err)で終了するだけでよい。
+
+// キャッシュのディレクトリを取得できない場合は一時ディレクトリを使う。
+func openCache() pjsekaioverlay.Cache {
+	cache, err := pjsekaioverlay.NewDefaultCache()
+	if err != nil {
+		return pjsekaioverlay.Cache{Dir: filepath.Join(os.TempDir(), "pjsekai-overlay")}
+	}
+	return cache
+}
+
+func fetchChart(cache pjsekaioverlay.Cache, chartId string) (pjsekaioverlay.Source, sonolus.LevelInfo, error) {
+	chartSource, err := pjsekaioverlay.DetectChartSource(chartId)
+	if err != nil {
+		return chartSource, sonolus.LevelInfo{}, failStage(stageFetchChart, err)
+	}
+	report.stageStarted(stageFetchChart, t("progress.fetchChart", RgbColorEscape(chartSource.Color)+chartSource.Name+ResetEscape()))
+	chart, err := cache.FetchChart(chartSource, chartId)
+	if err != nil {
+		return chartSource, chart, failStage(stageFetchChart, err)
+	}
+	if chart.Engine.Version != 13 {
+		err = pjsekaioverlay.NewError(pjsekaioverlay.ErrorUnsupportedEngine, nil, "error.unsupportedEngine", chart.Engine.Version)
+		return chartSource, chart, failStage(stageFetchChart, err)
+	}
+
+	report.stageFinished(stageFetchChart)
+	report.chart(chartId, chartSource, chart)
+
+	return chartSource, chart, nil
+}
+
+// キャッシュにある譜面の情報を使い、無い場合は取得する。
+func loadChart(cache pjsekaioverlay.Cache, chartId string) (pjsekaioverlay.Source, sonolus.LevelInfo, error) {
+	chartSource, err := pjsekaioverlay.DetectChartSource(chartId)
+	if err != nil {
+		return chartSource, sonolus.LevelInfo{}, failStage(stageFetchChart, err)
+	}
+	chart, err := cache.LoadChart(chartId)
+	if err != nil {
+		return fetchChart(cache, chartId)
+	}
+
+	report.chart(chartId, chartSource, chart)
+	return chartSource, chart, nil
+}
+
+// 出力先ディレクトリのテンプレートに値を埋め込み、既にある場合はflags.onExistsに従って決める。
+// canAskがfalseの場合、askは上書きになる。
+func resolveOutDir(flags outDirFlags, chartId string, chartSource pjsekaioverlay.Source, chart sonolus.LevelInfo, difficulty string, canAsk bool) (string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", failStage(stageOptions, err)
+	}
+
+	outDir, err := pjsekaioverlay.FormatOutDir(flags.template, pjsekaioverlay.OutDirValues{
+		ChartId:    chartId,
+		Title:      chart.Title,
+		Author:     chart.Author,
+		Source:     chartSource.Id,
+		Difficulty: difficulty,
+		Rating:     chart.Rating,
+		Date:       time.Now(),
+	})
+	if err != nil {
+		return "", failStage(stageOptions, err)
+	}
+	formattedOutDir := filepath.Join(cwd, outDir)
+
+	if entries, err := os.ReadDir(formattedOutDir); err == nil && len(entries) > 0 {
+		switch {
+		case flags.onExists == "suffix":
+			formattedOutDir = pjsekaioverlay.UniqueDir(formattedOutDir)
+		case flags.onExists == "ask" && canAsk:
+			if !promptYesNo(t("prompt.overwriteOutDir", formattedOutDir), true) {
+				formattedOutDir = pjsekaioverlay.UniqueDir(formattedOutDir)
+			}
+		}
+	}
+
+	report.info(t("common.outDir", color.CyanString(formattedOutDir)))
+	report.output("directory", formattedOutDir)
+	return formattedOutDir, nil
+}
+
+func downloadResources(chartSource pjsekaioverlay.Source, chart sonolus.LevelInfo, outDir string) error {
+	report.stageStarted(stageDownload, t("progress.cover"))
+	err := pjsekaioverlay.DownloadCover(chartSource, chart, outDir)
+	if err != nil {
+		return failStage(stageDownload, err)
+	}
+
+	report.stageFinished(stageDownload)
+	report.output("cover", filepath.Join(outDir, "cover.png"))
+
+	report.stageStarted(stageDownload, t("progress.background"))
+	err = pjsekaioverlay.DownloadBackground(chartSource, chart, outDir)
+	if err != nil {
+		return failStage(stageDownload, err)
+	}
+
+	report.stageFinished(stageDownload)
+	report.output("background", filepath.Join(outDir, "background.png"))
+	return nil
+}
+
+func loadLevelData(cache pjsekaioverlay.Cache, chartSource pjsekaioverlay.Source, chart sonolus.LevelInfo) (sonolus.LevelData, error) {
+	report.stageStarted(stageLevelData, t("progress.parseChart"))
+	levelData, err := cache.FetchLevelData(chartSource, chart)
+	if err != nil {
+		return levelData, failStage(stageLevelData, err)
+	}
+
+	report.stageFinished(stageLevelData)
+	return levelData, nil
+}
+
+func calculateScore(chart sonolus.LevelInfo, levelData sonolus.LevelData, flags *scoreFlags, skills []pjsekaioverlay.SkillWindow, judgeTable pjsekaioverlay.JudgeTable) (pjsekaioverlay.ScoreResult, error) {
+	weights, err := loadWeights(flags.weightsPath, chart.Engine.Name)
+	if err != nil {
+		return pjsekaioverlay.ScoreResult{}, failStage(stageScore, err)
+	}
+
+	unknownArchetypes := pjsekaioverlay.FindUnknownArchetypes(levelData, weights)
+	if len(unknownArchetypes) > 0 {
+		details := []string{}
+		for _, unknownArchetype := range unknownArchetypes {
+			details = append(details, fmt.Sprintf("%s: %d", unknownArchetype.Name, unknownArchetype.Count))
+		}
+		details = append(details, t("warning.addWeights", chart.Engine.Name))
+		report.warning("unknownArchetypes", t("warning.unknownArchetypes"), details)
+	}
+
+	judgements := map[int]pjsekaioverlay.Judgement{}
+	if flags.judgementsPath != "" {
+		report.stageStarted(stageJudgements, t("progress.judgements"))
+		judgements, err = pjsekaioverlay.LoadJudgements(flags.judgementsPath)
+		if err != nil {
+			return pjsekaioverlay.ScoreResult{}, failStage(stageJudgements, err)
+		}
+
+		report.stageFinished(stageJudgements)
+	}
+
+	report.stageStarted(stageScore, t("progress.score"))
+	scoreData := pjsekaioverlay.CalculateScore(chart, levelData, pjsekaioverlay.ScoreOptions{
+		Power:        flags.teamPower,
+		Judgements:   judgements,
+		Life:         judgeTable.Life,
+		JudgeWeights: judgeTable.Weights,
+		Weights:      weights,
+		Skills:       skills,
+	})
+
+	report.stageFinished(stageScore)
+
+	if len(scoreData.UntimedNotes) > 0 {
+		details := []string{}
+		for _, untimedNote := range scoreData.UntimedNotes {
+			details = append(details, fmt.Sprintf("#%d: %s", untimedNote.Index, untimedNote.Archetype))
+		}
+		report.warning("untimedNotes", t("warning.untimedNotes", len(scoreData.UntimedNotes)), details)
+	}
+
+	return scoreData, nil
+}
+
+func executableAssets() (string, error) {
+	executablePath, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(executablePath), "assets"), nil
+}
+
+// assetsが空の場合は出力先ディレクトリの中のassets、無ければexeと同じ場所のassetsを使う。
+func findAssets(outDir string, assets string) (string, error) {
+	if assets == "" {
+		assets = filepath.Join(outDir, pjsekaioverlay.PortableAssetsDir)
+		if _, err := os.Stat(assets); err != nil {
+			return executableAssets()
+		}
+	}
+	return filepath.Abs(assets)
+}
+
+// exoファイルに書き込むアセットのパスと、pedファイルに書き込むアセットのパスを返す。
+func resolveAssets(outDir string, portable bool) (string, string, error) {
+	assets, err := executableAssets()
+	if err != nil {
+		return "", "", failStage(stageAssets, err)
+	}
+	if !portable {
+		return assets, assets, nil
+	}
+
+	report.stageStarted(stageAssets, t("progress.copyAssets"))
+	assets, err = pjsekaioverlay.CopyAssets(assets, outDir)
+	if err != nil {
+		return "", "", failStage(stageAssets, err)
+	}
+
+	report.stageFinished(stageAssets)
+	report.output("assets", assets)
+	return assets, pjsekaioverlay.PedAssetsPath(assets, outDir), nil
+}
+
+func writePed(scoreData pjsekaioverlay.ScoreResult, outDir string, pedOptions pjsekaioverlay.PedOptions) error {
+	report.stageStarted(stagePed, t("progress.ped"))
+
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return failStage(stagePed, err)
+	}
+	pedPath := filepath.Join(outDir, "data.ped")
+	err := pjsekaioverlay.WritePedFile(scoreData, pedPath, pedOptions)
+	if err != nil {
+		return failStage(stagePed, err)
+	}
+
+	report.stageFinished(stagePed)
+	report.output("ped", pedPath)
+	return nil
+}
+
+// flagsで指定されたタイムラインとグラフを書き込む。
+func writeExtraOutputs(scoreData pjsekaioverlay.ScoreResult, chart sonolus.LevelInfo, outDir string, pedOptions pjsekaioverlay.PedOptions, flags extraOutputFlags) error {
+	for _, format := range strings.Split(flags.timelineFormats, ",") {
+		format = strings.TrimSpace(format)
+		if format == "" {
+			continue
+		}
+		report.stageStarted(stageTimeline, t("progress.timelineFormat", format))
+		timelinePath := filepath.Join(outDir, "timeline."+format)
+		err := pjsekaioverlay.WriteTimelineFile(scoreData, timelinePath, pedOptions, flags.timelineFps)
+		if err != nil {
+			return failStage(stageTimeline, err)
+		}
+
+		report.stageFinished(stageTimeline)
+		report.output("timeline", timelinePath)
+	}
+
+	if flags.graph {
+		report.stageStarted(stageGraph, t("progress.graph"))
+		graphPath := filepath.Join(outDir, "graph.png")
+		err := pjsekaioverlay.WriteScoreGraphFile(scoreData, graphPath, pjsekaioverlay.GraphOptions{
+			Width:     pjsekaioverlay.DefaultGraphWidth,
+			Height:    pjsekaioverlay.DefaultGraphHeight,
+			RankTable: pedOptions.RankTable,
+			Rating:    chart.Rating,
+			Density:   flags.graphDensity,
+		})
+		if err != nil {
+			return failStage(stageGraph, err)
+		}
+
+		report.stageFinished(stageGraph)
+		report.output("graph", graphPath)
+	}
+	return nil
+}
+
+func writeExo(chartSource pjsekaioverlay.Source, chart sonolus.LevelInfo, assets string, outDir string, exoOptions pjsekaioverlay.ExoOptions) error {
+	report.stageStarted(stageExo, t("progress.exo"))
+
+	composerAndVocals := []string{chart.Artists, "？"}
+	if separateAttempt := strings.Split(chart.Artists, " / "); chartSource.Id == "chart_cyanvas" && len(separateAttempt) == 2 {
+		composerAndVocals = separateAttempt
+	}
+
+	artists := fmt.Sprintf("作詞：？    作曲：%s    編曲：？\r\nVo：%s   譜面作成：%s", composerAndVocals[0], composerAndVocals[1], chart.Author)
+
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return failStage(stageExo, err)
+	}
+	err := pjsekaioverlay.WriteExoFiles(assets, outDir, chart.Title, artists, exoOptions)
+	if err != nil {
+		return failStage(stageExo, err)
+	}
+
+	report.stageFinished(stageExo)
+	report.output("exo", filepath.Join(outDir, "main.exo"))
+	return nil
+}
@@ -0,0 +1,171 @@
+package pjsekaioverlay
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sevenc-nanashi/pjsekai-overlay/pkg/sonolus"
+)
+
+// 譜面の情報と譜面データのキャッシュ。
+// 譜面データはハッシュ毎に保存するので、譜面が更新された場合は新しく取得される。
+type Cache struct {
+	Dir string
+}
+
+type CacheEntry struct {
+	// levels / levelData
+	Kind    string
+	Name    string
+	Size    int64
+	ModTime time.Time
+}
+
+const (
+	cacheLevelsDir    = "levels"
+	cacheLevelDataDir = "levelData"
+)
+
+func DefaultCacheDir() (string, error) {
+	userCacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", NewError(ErrorFilesystem, err, "error.cacheDir", err)
+	}
+	return filepath.Join(userCacheDir, "pjsekai-overlay"), nil
+}
+
+func NewDefaultCache() (Cache, error) {
+	dir, err := DefaultCacheDir()
+	if err != nil {
+		return Cache{}, err
+	}
+	return Cache{Dir: dir}, nil
+}
+
+// ファイル名に使えない文字を含むIDやハッシュは使わない
+func isCacheKey(key string) bool {
+	return key != "" && !strings.ContainsAny(key, "/\\:*?\"<>|") && key != "." && key != ".."
+}
+
+func (cache Cache) write(kind string, name string, data []byte) error {
+	dir := filepath.Join(cache.Dir, kind)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return NewError(ErrorFilesystem, err, "error.cacheWrite", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+		return NewError(ErrorFilesystem, err, "error.cacheWrite", err)
+	}
+	return nil
+}
+
+func (cache Cache) SaveChart(chartId string, chart sonolus.LevelInfo) error {
+	if !isCacheKey(chartId) {
+		return nil
+	}
+	data, err := json.Marshal(chart)
+	if err != nil {
+		return NewError(ErrorFilesystem, err, "error.cacheWrite", err)
+	}
+	return cache.write(cacheLevelsDir, chartId+".json", data)
+}
+
+// キャッシュに無い場合はfs.ErrNotExistを返す。
+func (cache Cache) LoadChart(chartId string) (sonolus.LevelInfo, error) {
+	if !isCacheKey(chartId) {
+		return sonolus.LevelInfo{}, fs.ErrNotExist
+	}
+	data, err := os.ReadFile(filepath.Join(cache.Dir, cacheLevelsDir, chartId+".json"))
+	if err != nil {
+		return sonolus.LevelInfo{}, err
+	}
+	var chart sonolus.LevelInfo
+	if err := json.Unmarshal(data, &chart); err != nil {
+		return sonolus.LevelInfo{}, NewError(ErrorDecode, err, "error.cacheRead", err)
+	}
+	return chart, nil
+}
+
+// 譜面の情報を取得してキャッシュに保存する。
+func (cache Cache) FetchChart(source Source, chartId string) (sonolus.LevelInfo, error) {
+	chart, err := FetchChart(source, chartId)
+	if err != nil {
+		return sonolus.LevelInfo{}, err
+	}
+	if err := cache.SaveChart(chartId, chart); err != nil {
+		return sonolus.LevelInfo{}, err
+	}
+	return chart, nil
+}
+
+// キャッシュにあればそれを使い、無ければ取得してキャッシュに保存する。
+func (cache Cache) FetchLevelData(source Source, level sonolus.LevelInfo) (sonolus.LevelData, error) {
+	hash := level.Data.Hash
+	if !isCacheKey(hash) {
+		return FetchLevelData(source, level)
+	}
+
+	path := filepath.Join(cache.Dir, cacheLevelDataDir, hash)
+	if rawData, err := os.ReadFile(path); err == nil {
+		levelData, err := decodeLevelData(rawData)
+		if err == nil {
+			return levelData, nil
+		}
+		// 壊れている場合は取得し直す
+	}
+
+	rawData, err := downloadLevelData(source, level)
+	if err != nil {
+		return sonolus.LevelData{}, err
+	}
+	levelData, err := decodeLevelData(rawData)
+	if err != nil {
+		return sonolus.LevelData{}, err
+	}
+	if err := cache.write(cacheLevelDataDir, hash, rawData); err != nil {
+		return sonolus.LevelData{}, err
+	}
+	return levelData, nil
+}
+
+func (cache Cache) List() ([]CacheEntry, error) {
+	entries := []CacheEntry{}
+	for _, kind := range []string{cacheLevelsDir, cacheLevelDataDir} {
+		dirEntries, err := os.ReadDir(filepath.Join(cache.Dir, kind))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, NewError(ErrorFilesystem, err, "error.cacheRead", err)
+		}
+		for _, dirEntry := range dirEntries {
+			info, err := dirEntry.Info()
+			if err != nil || dirEntry.IsDir() {
+				continue
+			}
+			entries = append(entries, CacheEntry{
+				Kind:    kind,
+				Name:    strings.TrimSuffix(dirEntry.Name(), ".json"),
+				Size:    info.Size(),
+				ModTime: info.ModTime(),
+			})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].ModTime.After(entries[j].ModTime)
+	})
+	return entries, nil
+}
+
+func (cache Cache) Clear() error {
+	for _, kind := range []string{cacheLevelsDir, cacheLevelDataDir} {
+		if err := os.RemoveAll(filepath.Join(cache.Dir, kind)); err != nil {
+			return NewError(ErrorFilesystem, err, "error.cacheClear", err)
+		}
+	}
+	return nil
+}
@@ -1,15 +1,13 @@
 package pjsekaioverlay
 
 import (
+	"bytes"
 	"compress/gzip"
 	"encoding/json"
-	"errors"
-	"fmt"
 	"image"
 	_ "image/jpeg"
 	"image/png"
 	"io"
-	"net/http"
 	"os"
 	"path"
 	"strings"
@@ -20,88 +18,114 @@ import (
 )
 
 type Source struct {
-	Id    string
-	Name  string
-	Color int
-	Host  string
+	Id    string `json:"id"`
+	Name  string `json:"name"`
+	Color int    `json:"color"`
+	Host  string `json:"host"`
+	// 譜面IDの先頭に付くプレフィックス
+	Prefix string `json:"prefix"`
+}
+
+var SOURCES = []Source{
+	{
+		Id:     "potato_leaves",
+		Name:   "Potato Leaves",
+		Color:  0x88cb7f,
+		Host:   "ptlv.sevenc7c.com",
+		Prefix: "ptlv-",
+	},
+	{
+		Id:     "chart_cyanvas",
+		Name:   "Chart Cyanvas",
+		Color:  0x83ccd2,
+		Host:   "cc.sevenc7c.com",
+		Prefix: "chcy-",
+	},
 }
 
 func FetchChart(source Source, chartId string) (sonolus.LevelInfo, error) {
 	var url = "https://" + source.Host + "/sonolus/levels/" + chartId
 
-	resp, err := http.Get(url)
+	resp, err := httpGet("chart", url)
 
 	if err != nil {
-		return sonolus.LevelInfo{}, errors.New("サーバーに接続できませんでした。")
+		return sonolus.LevelInfo{}, NewError(ErrorNetwork, err, "error.connect")
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return sonolus.LevelInfo{}, errors.New("譜面が見つかりませんでした。")
+		return sonolus.LevelInfo{}, NewError(ErrorChartNotFound, nil, "error.chartNotFound")
 	}
 
 	var chart sonolus.InfoResponse[sonolus.LevelInfo]
-	json.NewDecoder(resp.Body).Decode(&chart)
+	if err := json.NewDecoder(resp.Body).Decode(&chart); err != nil {
+		return sonolus.LevelInfo{}, NewError(ErrorDecode, err, "error.chartRead", err)
+	}
 
 	return chart.Item, nil
 }
 
 func DetectChartSource(chartId string) (Source, error) {
-	var source Source
-	if strings.HasPrefix(chartId, "ptlv-") {
-		source = Source{
-			Id:    "potato_leaves",
-			Name:  "Potato Leaves",
-			Color: 0x88cb7f,
-			Host:  "ptlv.sevenc7c.com",
+	for _, source := range SOURCES {
+		if strings.HasPrefix(chartId, source.Prefix) {
+			return source, nil
 		}
-	} else if strings.HasPrefix(chartId, "chcy-") {
-		source = Source{
-			Id:    "chart_cyanvas",
-			Name:  "Chart Cyanvas",
-			Color: 0x83ccd2,
-			Host:  "cc.sevenc7c.com",
-		}
-	}
-	if source.Id == "" {
-		return Source{
-			Id:    chartId,
-			Name:  "",
-			Color: 0,
-			Host:  "",
-		}, errors.New("unknown chart source")
 	}
-	return source, nil
+	return Source{
+		Id:    chartId,
+		Name:  "",
+		Color: 0,
+		Host:  "",
+	}, NewError(ErrorUnknownSource, nil, "error.unknownSource")
 }
 
 func FetchLevelData(source Source, level sonolus.LevelInfo) (sonolus.LevelData, error) {
+	rawData, err := downloadLevelData(source, level)
+	if err != nil {
+		return sonolus.LevelData{}, err
+	}
+
+	return decodeLevelData(rawData)
+}
+
+// gzipで圧縮されたままの譜面データを返す。
+func downloadLevelData(source Source, level sonolus.LevelInfo) ([]byte, error) {
 	url, err := sonolus.JoinUrl("https://"+source.Host, level.Data.Url)
 
 	if err != nil {
-		return sonolus.LevelData{}, fmt.Errorf("URLの解析に失敗しました。（%s）", err)
+		return nil, NewError(ErrorDecode, err, "error.parseUrl", err)
 	}
 
-	resp, err := http.Get(url)
+	resp, err := httpGet("levelData", url)
 
 	if err != nil {
-		return sonolus.LevelData{}, fmt.Errorf("サーバーに接続できませんでした。（%s）", err)
+		return nil, NewError(ErrorNetwork, err, "error.connectCause", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return sonolus.LevelData{}, fmt.Errorf("譜面データが見つかりませんでした。（%d）", resp.StatusCode)
+		return nil, NewError(ErrorNetwork, nil, "error.levelDataNotFound", resp.StatusCode)
 	}
 
+	rawData, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, NewError(ErrorNetwork, err, "error.levelDataDownload", err)
+	}
+
+	return rawData, nil
+}
+
+func decodeLevelData(rawData []byte) (sonolus.LevelData, error) {
 	var data sonolus.LevelData
-	gzipReader, err := gzip.NewReader(resp.Body)
+	gzipReader, err := gzip.NewReader(bytes.NewReader(rawData))
 	if err != nil {
-		return sonolus.LevelData{}, fmt.Errorf("譜面データの読み込みに失敗しました。（%s）", err)
+		return sonolus.LevelData{}, NewError(ErrorDecode, err, "error.levelDataRead", err)
 	}
 
 	err = json.NewDecoder(gzipReader).Decode(&data)
 
 	if err != nil {
-		return sonolus.LevelData{}, fmt.Errorf("譜面データの読み込みに失敗しました。（%s）", err)
+		return sonolus.LevelData{}, NewError(ErrorDecode, err, "error.levelDataRead", err)
 	}
 
 	return data, nil
@@ -111,26 +135,26 @@ func DownloadCover(source Source, level sonolus.LevelInfo, destPath string) erro
 	url, err := sonolus.JoinUrl("https://"+source.Host, level.Cover.Url)
 
 	if err != nil {
-		return fmt.Errorf("URLの解析に失敗しました。（%s）", err)
+		return NewError(ErrorDecode, err, "error.parseUrl", err)
 	}
 
-	resp, err := http.Get(url)
+	resp, err := httpGet("cover", url)
 
 	if err != nil {
-		return fmt.Errorf("サーバーに接続できませんでした。（%s）", err)
+		return NewError(ErrorNetwork, err, "error.connectCause", err)
 	}
 
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return fmt.Errorf("ジャケットが見つかりませんでした。（%d）", resp.StatusCode)
+		return NewError(ErrorNetwork, nil, "error.coverNotFound", resp.StatusCode)
 	}
 
 	os.MkdirAll(destPath, 0755)
 	imageData, _, err := image.Decode(resp.Body)
 
 	if err != nil {
-		return fmt.Errorf("ジャケットの読み込みに失敗しました。（%s）", err)
+		return NewError(ErrorDecode, err, "error.coverRead", err)
 	}
 
 	// 画像のリサイズ
@@ -142,7 +166,7 @@ func DownloadCover(source Source, level sonolus.LevelInfo, destPath string) erro
 	file, err := os.Create(path.Join(destPath, "cover.png"))
 
 	if err != nil {
-		return fmt.Errorf("ファイルの作成に失敗しました。（%s）", err)
+		return NewError(ErrorFilesystem, err, "error.createFile", err)
 	}
 
 	defer file.Close()
@@ -150,7 +174,7 @@ func DownloadCover(source Source, level sonolus.LevelInfo, destPath string) erro
 	err = png.Encode(file, newImage)
 
 	if err != nil {
-		return fmt.Errorf("ファイルの書き込みに失敗しました。（%s）", err)
+		return NewError(ErrorFilesystem, err, "error.writeFile", err)
 	}
 
 	return nil
@@ -160,30 +184,34 @@ func DownloadBackground(source Source, level sonolus.LevelInfo, destPath string)
 	var err error
 	backgroundUrl, err = sonolus.JoinUrl("https://"+source.Host, level.UseBackground.Item.Image.Url)
 
-	resp, err := http.Get(backgroundUrl)
+	if err != nil {
+		return NewError(ErrorDecode, err, "error.parseUrl", err)
+	}
+
+	resp, err := httpGet("background", backgroundUrl)
 
 	if err != nil {
-		return fmt.Errorf("サーバーに接続できませんでした。（%s）", err)
+		return NewError(ErrorNetwork, err, "error.connectCause", err)
 	}
 
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return fmt.Errorf("背景が見つかりませんでした。（%d）", resp.StatusCode)
+		return NewError(ErrorNetwork, nil, "error.backgroundNotFound", resp.StatusCode)
 	}
 
 	file, err := os.Create(path.Join(destPath, "background.png"))
 
 	if err != nil {
-		return fmt.Errorf("ファイルの作成に失敗しました。（%s）", err)
+		return NewError(ErrorFilesystem, err, "error.createFile", err)
 	}
 
 	defer file.Close()
 
-	io.Copy(file, resp.Body)
+	_, err = io.Copy(file, resp.Body)
 
 	if err != nil {
-		return fmt.Errorf("ファイルの書き込みに失敗しました。（%s）", err)
+		return NewError(ErrorFilesystem, err, "error.writeFile", err)
 	}
 
 	return nil
@@ -0,0 +1,38 @@
+package pjsekaioverlay
+
+// コンボが加算されないノーツ（重み0のノーツはそもそも数えない）
+var NON_COMBO_ARCHETYPES = map[string]bool{
+	"DamageNote": true,
+}
+
+// Good以下でコンボが切れる
+var COMBO_BREAK_JUDGEMENTS = map[Judgement]bool{
+	JudgementGood: true,
+	JudgementBad:  true,
+	JudgementMiss: true,
+}
+
+func countsCombo(archetype string, weight float64) bool {
+	return weight > 0 && !NON_COMBO_ARCHETYPES[archetype]
+}
+
+func nextCombo(combo int, archetype string, weight float64, judgement Judgement) int {
+	if COMBO_BREAK_JUDGEMENTS[judgement] {
+		return 0
+	}
+	if !countsCombo(archetype, weight) {
+		return combo
+	}
+	return combo + 1
+}
+
+func getComboFax(combo int) float64 {
+	// 100コンボ毎に1%、最大10%
+	bonus := (combo - 1) / 100
+	if bonus < 0 {
+		bonus = 0
+	} else if bonus > 10 {
+		bonus = 10
+	}
+	return 1 + float64(bonus)*0.01
+}
@@ -0,0 +1,171 @@
+package pjsekaioverlay
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// 設定ファイル。optionsとprofilesの値はコマンドのオプション名と値の組。
+//
+//	{
+//	  "lang": "en",
+//	  "profile": "youtube-1080p",
+//	  "options": { "team-power": 300000, "ap-combo": false },
+//	  "profiles": {
+//	    "youtube-1080p": { "resolution": "1920x1080", "out-dir": "./youtube/_chartId_" }
+//	  },
+//	  "sources": [
+//	    { "id": "my_server", "name": "My Server", "color": 16777215, "host": "example.com", "prefix": "my-" }
+//	  ]
+//	}
+type Config struct {
+	// メッセージの言語
+	Lang string `json:"lang"`
+	// 既定のプロファイル
+	Profile  string                       `json:"profile"`
+	Options  map[string]string            `json:"options"`
+	Profiles map[string]map[string]string `json:"profiles"`
+	Sources  []Source                     `json:"sources"`
+}
+
+const ProjectConfigName = "pjsekai-overlay.json"
+
+// ユーザー毎の設定ファイルと、カレントディレクトリの設定ファイルのパス。後のものが優先される。
+func ConfigPaths() []string {
+	paths := []string{}
+	if userConfigDir, err := os.UserConfigDir(); err == nil {
+		paths = append(paths, filepath.Join(userConfigDir, "pjsekai-overlay", "config.json"))
+	}
+	if cwd, err := os.Getwd(); err == nil {
+		paths = append(paths, filepath.Join(cwd, ProjectConfigName))
+	}
+	return paths
+}
+
+// JSONの数値や真偽値も文字列として読み込む
+func decodeConfigOptions(raw map[string]json.RawMessage) (map[string]string, error) {
+	options := make(map[string]string, len(raw))
+	for name, value := range raw {
+		var decoded any
+		decoder := json.NewDecoder(bytes.NewReader(value))
+		decoder.UseNumber()
+		if err := decoder.Decode(&decoded); err != nil {
+			return nil, err
+		}
+		switch decoded := decoded.(type) {
+		case string:
+			options[name] = decoded
+		case json.Number, bool:
+			options[name] = fmt.Sprint(decoded)
+		default:
+			return nil, Errorf("error.configValue", name)
+		}
+	}
+	return options, nil
+}
+
+func ParseConfig(data []byte) (Config, error) {
+	var raw struct {
+		Lang     string                                `json:"lang"`
+		Profile  string                                `json:"profile"`
+		Options  map[string]json.RawMessage            `json:"options"`
+		Profiles map[string]map[string]json.RawMessage `json:"profiles"`
+		Sources  []Source                              `json:"sources"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return Config{}, err
+	}
+
+	config := Config{Lang: raw.Lang, Profile: raw.Profile, Profiles: map[string]map[string]string{}, Sources: raw.Sources}
+	var err error
+	config.Options, err = decodeConfigOptions(raw.Options)
+	if err != nil {
+		return Config{}, err
+	}
+	for name, rawProfile := range raw.Profiles {
+		config.Profiles[name], err = decodeConfigOptions(rawProfile)
+		if err != nil {
+			return Config{}, Errorf("error.configProfile", name, err)
+		}
+	}
+	for _, source := range config.Sources {
+		if source.Id == "" || source.Host == "" || source.Prefix == "" {
+			return Config{}, Errorf("error.configSources")
+		}
+	}
+
+	return config, nil
+}
+
+// 存在しないファイルは無視して、順に読み込んだ設定を重ねる。
+func LoadConfig(paths ...string) (Config, error) {
+	config := Config{Options: map[string]string{}, Profiles: map[string]map[string]string{}}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return Config{}, NewError(ErrorFilesystem, err, "error.configOpen", err)
+		}
+		fileConfig, err := ParseConfig(data)
+		if err != nil {
+			return Config{}, NewError(ErrorDecode, err, "error.configRead", path, err)
+		}
+		config.merge(fileConfig)
+	}
+	return config, nil
+}
+
+func (config *Config) merge(other Config) {
+	if other.Lang != "" {
+		config.Lang = other.Lang
+	}
+	if other.Profile != "" {
+		config.Profile = other.Profile
+	}
+	for name, value := range other.Options {
+		config.Options[name] = value
+	}
+	for name, profile := range other.Profiles {
+		if config.Profiles[name] == nil {
+			config.Profiles[name] = map[string]string{}
+		}
+		for optionName, value := range profile {
+			config.Profiles[name][optionName] = value
+		}
+	}
+	// 後に読み込んだ設定ファイルのサーバーを優先する
+	config.Sources = append(append([]Source{}, other.Sources...), config.Sources...)
+}
+
+// optionsにプロファイルの値を重ねたものを返す。profileが空の場合は既定のプロファイルを使う。
+func (config Config) ResolveOptions(profile string) (map[string]string, error) {
+	if profile == "" {
+		profile = config.Profile
+	}
+	options := make(map[string]string, len(config.Options))
+	for name, value := range config.Options {
+		options[name] = value
+	}
+	if profile == "" {
+		return options, nil
+	}
+	profileOptions, ok := config.Profiles[profile]
+	if !ok {
+		return nil, Errorf("error.profileNotFound", profile)
+	}
+	for name, value := range profileOptions {
+		options[name] = value
+	}
+	return options, nil
+}
+
+// 設定ファイルのサーバーを追加する。同じプレフィックスのサーバーは設定ファイルのものが優先される。
+func RegisterSources(sources []Source) {
+	SOURCES = append(append([]Source{}, sources...), SOURCES...)
+}
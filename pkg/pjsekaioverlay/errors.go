@@ -0,0 +1,46 @@
+package pjsekaioverlay
+
+import "errors"
+
+// エラーの種類。コマンドの終了コードと--jsonのエラーコードに使う。
+type ErrorKind string
+
+const (
+	ErrorUnknownSource     ErrorKind = "unknownSource"
+	ErrorChartNotFound     ErrorKind = "chartNotFound"
+	ErrorNetwork           ErrorKind = "network"
+	ErrorUnsupportedEngine ErrorKind = "unsupportedEngine"
+	ErrorDecode            ErrorKind = "decode"
+	ErrorFilesystem        ErrorKind = "filesystem"
+	ErrorEncoding          ErrorKind = "encoding"
+)
+
+// 種類と原因を持つエラー。メッセージは現在の言語で作る。
+type Error struct {
+	Kind    ErrorKind
+	Message string
+	// 原因が無い場合はnil
+	Cause error
+}
+
+func (err *Error) Error() string {
+	return err.Message
+}
+
+func (err *Error) Unwrap() error {
+	return err.Cause
+}
+
+// keyとargsはErrorfと同じ。
+func NewError(kind ErrorKind, cause error, key string, args ...any) error {
+	return &Error{Kind: kind, Message: Errorf(key, args...).Error(), Cause: cause}
+}
+
+// エラーの種類を返す。種類が無い場合は空文字列。
+func KindOf(err error) ErrorKind {
+	var typed *Error
+	if errors.As(err, &typed) {
+		return typed.Kind
+	}
+	return ""
+}
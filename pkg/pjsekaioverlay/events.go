@@ -0,0 +1,163 @@
+package pjsekaioverlay
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type TimeWindow struct {
+	Start float64
+	End   float64
+}
+
+// スキルの発動期間。Bonusは0.5で+50%。
+type SkillWindow struct {
+	TimeWindow
+	Bonus float64
+}
+
+type PedEvent struct {
+	Time float64
+	Type string
+	Args []string
+}
+
+const (
+	PedEventNote       = "note"
+	PedEventComboBreak = "combo_break"
+	PedEventLife       = "life"
+	PedEventRank       = "rank"
+	PedEventSkill      = "skill"
+	PedEventFever      = "fever"
+)
+
+func (window TimeWindow) Contains(time float64) bool {
+	return time >= window.Start && time < window.End
+}
+
+// 「12.5,40,70」のような発動時間のリストをスキルの期間に変換する。
+func ParseSkillWindows(spec string, duration float64, bonus float64) ([]SkillWindow, error) {
+	skillWindows := []SkillWindow{}
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		start, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, Errorf("error.invalidSkillTime", field)
+		}
+		skillWindows = append(skillWindows, SkillWindow{
+			TimeWindow: TimeWindow{Start: start, End: start + duration},
+			Bonus:      bonus,
+		})
+	}
+	return skillWindows, nil
+}
+
+// 「90-110」のような期間を変換する。
+func ParseTimeWindow(spec string) (TimeWindow, error) {
+	start, end, found := strings.Cut(spec, "-")
+	if !found {
+		return TimeWindow{}, Errorf("error.invalidTimeWindow", spec)
+	}
+	startTime, err := strconv.ParseFloat(strings.TrimSpace(start), 64)
+	if err != nil {
+		return TimeWindow{}, Errorf("error.invalidTimeWindow", spec)
+	}
+	endTime, err := strconv.ParseFloat(strings.TrimSpace(end), 64)
+	if err != nil || endTime < startTime {
+		return TimeWindow{}, Errorf("error.invalidTimeWindow", spec)
+	}
+	return TimeWindow{Start: startTime, End: endTime}, nil
+}
+
+// ノーツの種類（tap / flick / slide / tick / trace / damage）
+func NoteKind(archetype string) string {
+	switch {
+	case isDamageNote(archetype):
+		return "damage"
+	case strings.Contains(archetype, "Flick"):
+		return "flick"
+	case strings.Contains(archetype, "Trace"):
+		return "trace"
+	case strings.Contains(archetype, "Tick"):
+		return "tick"
+	case strings.Contains(archetype, "Slide"):
+		return "slide"
+	default:
+		return "tap"
+	}
+}
+
+func isCriticalNote(archetype string) bool {
+	return strings.HasPrefix(archetype, "Critical")
+}
+
+func BuildPedEvents(scoreData ScoreResult, options PedOptions) []PedEvent {
+	events := []PedEvent{}
+
+	combo := 0
+	life := options.Life.Initial
+	for _, note := range scoreData.Notes {
+		critical := "0"
+		if isCriticalNote(note.Archetype) {
+			critical = "1"
+		}
+		events = append(events, PedEvent{
+			Time: note.Time,
+			Type: PedEventNote,
+			Args: []string{note.Judgement.String(), NoteKind(note.Archetype), critical, note.Archetype},
+		})
+		if COMBO_BREAK_JUDGEMENTS[note.Judgement] && combo > 0 {
+			events = append(events, PedEvent{
+				Time: note.Time,
+				Type: PedEventComboBreak,
+				Args: []string{strconv.Itoa(combo)},
+			})
+		}
+		if note.Life != life {
+			events = append(events, PedEvent{
+				Time: note.Time,
+				Type: PedEventLife,
+				Args: []string{strconv.Itoa(note.Life - life), strconv.Itoa(note.Life)},
+			})
+		}
+		combo = note.Combo
+		life = note.Life
+	}
+
+	lastRank := ""
+	for _, frame := range scoreData.Frames {
+		rank, _ := options.RankTable.Rank(frame.Score, options.LevelInfo.Rating)
+		if lastRank != "" && rank != lastRank {
+			events = append(events, PedEvent{
+				Time: frame.Time,
+				Type: PedEventRank,
+				Args: []string{rank},
+			})
+		}
+		lastRank = rank
+	}
+
+	for _, skill := range options.Skills {
+		events = append(events, PedEvent{
+			Time: skill.Start,
+			Type: PedEventSkill,
+			Args: []string{formatPedFloat(skill.End - skill.Start), formatPedFloat(skill.Bonus)},
+		})
+	}
+	if options.Fever != nil {
+		events = append(events, PedEvent{
+			Time: options.Fever.Start,
+			Type: PedEventFever,
+			Args: []string{formatPedFloat(options.Fever.End - options.Fever.Start)},
+		})
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time < events[j].Time
+	})
+	return events
+}
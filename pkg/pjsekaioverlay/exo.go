@@ -4,8 +4,10 @@ import (
 	_ "embed"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"unicode/utf16"
 
@@ -13,11 +15,11 @@ import (
 	"golang.org/x/text/transform"
 )
 
-func encodeString(str string) string {
+func encodeString(str string) (string, error) {
 	bytes := utf16.Encode([]rune(str))
 	encoded := make([]string, 1024)
-	if len(str) > 1024 {
-		panic("too long string")
+	if len(bytes) > 1024 {
+		return "", NewError(ErrorEncoding, nil, "error.tooLongText", len(bytes), len(encoded))
 	}
 	for i := range encoded {
 		var hex string
@@ -30,28 +32,167 @@ func encodeString(str string) string {
 		encoded[i] = hex
 	}
 
-	return strings.Join(encoded, "")
+	return strings.Join(encoded, ""), nil
 }
 
 //go:embed main.exo
 var rawBaseExo []byte
 
-func WriteExoFiles(assets string, destDir string, title string, description string) error {
+type ExoOptions struct {
+	// 空の場合はMASTER
+	Difficulty string
+	// master / append。空の場合は両方の背景を残す
+	Theme string
+	// 0の場合はmain.exoのまま。main.exoと同じ縦横比でなければならない
+	Width  int
+	Height int
+}
+
+// main.exoの解像度
+const (
+	baseExoWidth  = 1334
+	baseExoHeight = 750
+)
+
+// 縦横比の許容する誤差。1920x1080などの16:9はmain.exoの1334x750と同じとみなす
+const resolutionAspectTolerance = 0.01
+
+var EXO_THEMES = map[string]string{
+	"master": "master_bg.png",
+	"append": "append_bg.png",
+}
+
+// 「1920x1080」のような解像度を変換する。
+func ParseResolution(spec string) (int, int, error) {
+	widthText, heightText, found := strings.Cut(strings.ToLower(spec), "x")
+	if !found {
+		return 0, 0, Errorf("error.invalidResolution", spec)
+	}
+	width, err := strconv.Atoi(strings.TrimSpace(widthText))
+	if err != nil || width <= 0 {
+		return 0, 0, Errorf("error.invalidResolution", spec)
+	}
+	height, err := strconv.Atoi(strings.TrimSpace(heightText))
+	if err != nil || height <= 0 {
+		return 0, 0, Errorf("error.invalidResolution", spec)
+	}
+	if err := checkResolution(width, height); err != nil {
+		return 0, 0, err
+	}
+	return width, height, nil
+}
+
+// オブジェクトは拡大するだけなので、縦横比がmain.exoと違う解像度は使えない。
+func checkResolution(width int, height int) error {
+	aspect := float64(width) / float64(height)
+	baseAspect := float64(baseExoWidth) / float64(baseExoHeight)
+	if math.Abs(aspect-baseAspect)/baseAspect > resolutionAspectTolerance {
+		return Errorf("error.resolutionAspect", width, height)
+	}
+	return nil
+}
+
+// 「25.0,0.0,15@減速@加減速TRA,2」のようなトラックバーの値の、開始と終了の値をscale倍する。
+// 小数点以下はminPrecision桁か元の値の桁数の多い方で書く。
+func scaleTrackValue(value string, scale float64, minPrecision int) string {
+	parts := strings.SplitN(value, ",", 3)
+	for i := 0; i < len(parts) && i < 2; i++ {
+		number, err := strconv.ParseFloat(parts[i], 64)
+		if err != nil {
+			continue
+		}
+		precision := minPrecision
+		if _, decimals, found := strings.Cut(parts[i], "."); found && len(decimals) > precision {
+			precision = len(decimals)
+		}
+		parts[i] = strconv.FormatFloat(number*scale, 'f', precision, 64)
+	}
+	return strings.Join(parts, ",")
+}
+
+// main.exoを指定した解像度に合わせる。
+// 全てのオブジェクトの座標と拡大率をscale倍する。グループ制御の拡大率は子のオブジェクトにも
+// 掛かるので、二重に拡大しないように座標だけをscale倍する。
+func scaleExo(exo string, width int, height int) (string, error) {
+	if err := checkResolution(width, height); err != nil {
+		return "", err
+	}
+	scale := float64(height) / baseExoHeight
+	lines := strings.Split(exo, "\n")
+	section := ""
+	for i, line := range lines {
+		if strings.HasPrefix(line, "[") {
+			section = strings.TrimSuffix(strings.TrimPrefix(line, "["), "]")
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		switch {
+		case key == "_name":
+			section = value
+		case section == "exedit" && key == "width":
+			lines[i] = "width=" + strconv.Itoa(width)
+		case section == "exedit" && key == "height":
+			lines[i] = "height=" + strconv.Itoa(height)
+		case (section == "標準描画" || section == "グループ制御") && (key == "X" || key == "Y"):
+			lines[i] = key + "=" + scaleTrackValue(value, scale, 1)
+		case section == "標準描画" && key == "拡大率":
+			lines[i] = key + "=" + scaleTrackValue(value, scale, 2)
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func WriteExoFiles(assets string, destDir string, title string, description string, options ExoOptions) error {
 	baseExo := string(rawBaseExo)
 	replacedExo := baseExo
+
+	difficulty := options.Difficulty
+	if difficulty == "" {
+		difficulty = "MASTER"
+	}
+	if options.Theme != "" {
+		background, ok := EXO_THEMES[options.Theme]
+		if !ok {
+			return Errorf("error.invalidTheme", options.Theme)
+		}
+		for _, otherBackground := range EXO_THEMES {
+			replacedExo = strings.ReplaceAll(replacedExo, "file={assets}\\"+otherBackground, "file={assets}\\"+background)
+		}
+	}
+	if options.Width > 0 && options.Height > 0 {
+		var err error
+		replacedExo, err = scaleExo(replacedExo, options.Width, options.Height)
+		if err != nil {
+			return err
+		}
+	}
+
+	texts := []string{difficulty, title, description}
+	encodedTexts := make([]string, len(texts))
+	for i, text := range texts {
+		encodedText, err := encodeString(text)
+		if err != nil {
+			return err
+		}
+		encodedTexts[i] = encodedText
+	}
+
 	mapping := []string{
 		"{assets}", strings.ReplaceAll(assets, "\\", "/"),
 		"{dist}", strings.ReplaceAll(destDir, "\\", "/"),
-		"{text:difficulty}", encodeString("MASTER"),
-		"{text:title}", encodeString(title),
-		"{text:description}", encodeString(description),
+		"{text:difficulty}", encodedTexts[0],
+		"{text:title}", encodedTexts[1],
+		"{text:description}", encodedTexts[2],
 	}
 	for i := range mapping {
 		if i%2 == 0 {
 			continue
 		}
 		if !strings.Contains(replacedExo, mapping[i-1]) {
-			panic(fmt.Sprintf("exoファイルの生成に失敗しました（%sが見つかりません）", mapping[i-1]))
+			return NewError(ErrorEncoding, nil, "error.exoPlaceholder", mapping[i-1])
 		}
 		replacedExo = strings.ReplaceAll(replacedExo, mapping[i-1], mapping[i])
 	}
@@ -59,12 +200,12 @@ func WriteExoFiles(assets string, destDir string, title string, description stri
 	encodedExo, err := io.ReadAll(transform.NewReader(
 		strings.NewReader(replacedExo), japanese.ShiftJIS.NewEncoder()))
 	if err != nil {
-		return fmt.Errorf("エンコードに失敗しました（%w）", err)
+		return NewError(ErrorEncoding, err, "error.encode", err)
 	}
 	if err := os.WriteFile(filepath.Join(destDir, "main.exo"),
 		encodedExo,
 		0644); err != nil {
-		return fmt.Errorf("ファイルの書き込みに失敗しました（%w）", err)
+		return NewError(ErrorFilesystem, err, "error.writeFile", err)
 	}
 
 	return nil
@@ -0,0 +1,84 @@
+package pjsekaioverlay
+
+import (
+	"math"
+	"strconv"
+)
+
+// 動画のフレーム毎の状態の表。Frame以降のフレームでは、Score番目のsレコードと
+// Note番目の判定を表示するノーツが現在の状態になる（どちらも1から数え、0は無し）。
+type PedFrameRun struct {
+	Frame int
+	Score int
+	Note  int
+}
+
+type PedFrameTable struct {
+	Fps  float64
+	Runs []PedFrameRun
+}
+
+// pedファイルに書き込まれる精度に丸める
+func roundPedTime(time float64) float64 {
+	rounded, _ := strconv.ParseFloat(formatPedFloat(time), 64)
+	return rounded
+}
+
+// 判定を表示するノーツ（避けたダメージノーツ以外）の時間
+func displayedNoteTimes(events []PedEvent) []float64 {
+	times := []float64{}
+	for _, event := range events {
+		if event.Type != PedEventNote || len(event.Args) < 2 {
+			continue
+		}
+		if event.Args[1] == "damage" && event.Args[0] == JudgementPerfect.String() {
+			continue
+		}
+		times = append(times, roundPedTime(event.Time))
+	}
+	return times
+}
+
+// sekai.objと同じく、「時間 * fps < フレーム」を満たす最後の状態を選ぶ。
+func BuildPedFrameTable(scores []PedScore, events []PedEvent, fps float64) *PedFrameTable {
+	noteTimes := displayedNoteTimes(events)
+	lastTime := 0.0
+	if len(scores) > 0 {
+		lastTime = math.Max(lastTime, roundPedTime(scores[len(scores)-1].Time))
+	}
+	if len(noteTimes) > 0 {
+		lastTime = math.Max(lastTime, noteTimes[len(noteTimes)-1])
+	}
+	lastFrame := int(math.Ceil(lastTime*fps)) + 1
+	// 最初のノーツの時間が負の場合、その時間のフレームから表を始める。
+	// このフレームではまだ何も選ばれないので、sekai.objでこれより前のフレームは既定の状態になる。
+	firstTime := 0.0
+	if len(scores) > 0 {
+		firstTime = math.Min(firstTime, roundPedTime(scores[0].Time))
+	}
+	if len(noteTimes) > 0 {
+		firstTime = math.Min(firstTime, noteTimes[0])
+	}
+	firstFrame := int(math.Floor(firstTime * fps))
+
+	table := &PedFrameTable{Fps: fps, Runs: []PedFrameRun{}}
+	scoreIndex := 0
+	noteIndex := 0
+	for frame := firstFrame; frame <= lastFrame; frame++ {
+		for scoreIndex < len(scores) && roundPedTime(scores[scoreIndex].Time)*fps < float64(frame) {
+			scoreIndex++
+		}
+		for noteIndex < len(noteTimes) && noteTimes[noteIndex]*fps < float64(frame) {
+			noteIndex++
+		}
+		if len(table.Runs) > 0 {
+			lastRun := table.Runs[len(table.Runs)-1]
+			if lastRun.Score == scoreIndex && lastRun.Note == noteIndex {
+				continue
+			}
+		}
+		table.Runs = append(table.Runs, PedFrameRun{Frame: frame, Score: scoreIndex, Note: noteIndex})
+	}
+
+	return table
+}
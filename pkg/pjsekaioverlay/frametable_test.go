@@ -0,0 +1,77 @@
+package pjsekaioverlay
+
+import (
+	"bytes"
+	"testing"
+)
+
+// sekai.objと同じく表から状態を探す。最初のレコードより前のフレームは何も選ばれない。
+func lookupPedFrameTable(table *PedFrameTable, frame int) (int, int) {
+	for i := len(table.Runs) - 1; i >= 0; i-- {
+		if table.Runs[i].Frame <= frame {
+			return table.Runs[i].Score, table.Runs[i].Note
+		}
+	}
+	return 0, 0
+}
+
+// sekai.objと同じく、表を使わずに「時間 * fps < フレーム」を満たす最後の状態を探す。
+func lookupPedLinear(scores []PedScore, noteTimes []float64, fps float64, frame int) (int, int) {
+	scoreIndex := 0
+	for i, score := range scores {
+		if roundPedTime(score.Time)*fps < float64(frame) {
+			scoreIndex = i + 1
+		}
+	}
+	noteIndex := 0
+	for i, time := range noteTimes {
+		if time*fps < float64(frame) {
+			noteIndex = i + 1
+		}
+	}
+	return scoreIndex, noteIndex
+}
+
+func TestFrameTableMatchesLinearLookupWithNegativeTimes(t *testing.T) {
+	const fps = 60.0
+	scores := []PedScore{
+		{Time: -0.500001, Rank: "d"},
+		{Time: -0.5, Score: 3312, Delta: 3312, Rank: "d"},
+		{Time: 0.25, Score: 6625, Delta: 3313, Rank: "d"},
+	}
+	events := []PedEvent{
+		{Time: -0.5, Type: PedEventNote, Args: []string{"perfect", "tap"}},
+		{Time: 0.25, Type: PedEventNote, Args: []string{"great", "tap"}},
+	}
+
+	table := BuildPedFrameTable(scores, events, fps)
+	if table.Runs[0].Frame >= 0 {
+		t.Fatalf("first run starts at frame %d, want a negative frame", table.Runs[0].Frame)
+	}
+	noteTimes := displayedNoteTimes(events)
+	for frame := table.Runs[0].Frame - 3; frame <= table.Runs[len(table.Runs)-1].Frame+3; frame++ {
+		tableScore, tableNote := lookupPedFrameTable(table, frame)
+		linearScore, linearNote := lookupPedLinear(scores, noteTimes, fps, frame)
+		if tableScore != linearScore || tableNote != linearNote {
+			t.Errorf("frame %d: table = %d:%d, linear = %d:%d", frame, tableScore, tableNote, linearScore, linearNote)
+		}
+	}
+
+	// 負のフレームも書き込んで読み込めること
+	var buffer bytes.Buffer
+	if err := WritePed(&buffer, PedFile{FormatVersion: PedFormatVersion, Scores: scores, Events: events, FrameTable: table}); err != nil {
+		t.Fatalf("WritePed: %v", err)
+	}
+	pedFile, err := ParsePed(&buffer)
+	if err != nil {
+		t.Fatalf("ParsePed: %v", err)
+	}
+	if pedFile.FrameTable == nil || len(pedFile.FrameTable.Runs) != len(table.Runs) {
+		t.Fatalf("FrameTable = %+v, want %+v", pedFile.FrameTable, table)
+	}
+	for i, run := range pedFile.FrameTable.Runs {
+		if run != table.Runs[i] {
+			t.Errorf("Runs[%d] = %+v, want %+v", i, run, table.Runs[i])
+		}
+	}
+}
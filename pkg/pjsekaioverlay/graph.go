@@ -0,0 +1,223 @@
+package pjsekaioverlay
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"os"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/sevenc-nanashi/pjsekai-overlay/pkg/sonolus"
+)
+
+const (
+	DefaultGraphWidth  = 1280
+	DefaultGraphHeight = 480
+)
+
+type GraphOptions struct {
+	Width  int
+	Height int
+	// ランクのボーダーの線に使う
+	RankTable RankTable
+	Rating    int
+	// スコアの下にノーツ密度のヒストグラムを描く
+	Density bool
+}
+
+var (
+	graphBackgroundColor = color.RGBA{0x1c, 0x1c, 0x2b, 0xff}
+	graphAxisColor       = color.RGBA{0x80, 0x80, 0x99, 0xff}
+	graphGridColor       = color.RGBA{0x2c, 0x2c, 0x40, 0xff}
+	graphTextColor       = color.RGBA{0xdd, 0xdd, 0xee, 0xff}
+	graphScoreColor      = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	graphDensityColor    = color.RGBA{0x53, 0x53, 0x6b, 0xff}
+)
+
+var RANK_BORDER_COLORS = map[string]color.RGBA{
+	"s": {0xff, 0xd7, 0x4f, 0xff},
+	"a": {0xff, 0x72, 0xb6, 0xff},
+	"b": {0x5c, 0xb8, 0xff, 0xff},
+	"c": {0x7c, 0xe0, 0x8a, 0xff},
+}
+
+const (
+	graphMarginLeft   = 72
+	graphMarginRight  = 16
+	graphMarginTop    = 16
+	graphMarginBottom = 24
+	graphDensityRatio = 0.25
+)
+
+// 目盛りの間隔（秒）。10本以下になる最小のものを使う。
+var graphTimeSteps = []float64{5, 10, 15, 30, 60, 120, 300}
+
+func fillGraphRect(img *image.RGBA, rect image.Rectangle, c color.Color) {
+	draw.Draw(img, rect.Intersect(img.Bounds()), &image.Uniform{c}, image.Point{}, draw.Src)
+}
+
+func drawGraphText(img *image.RGBA, x int, y int, text string, c color.Color) {
+	drawer := font.Drawer{
+		Dst:  img,
+		Src:  &image.Uniform{c},
+		Face: basicfont.Face7x13,
+		Dot:  fixed.P(x, y),
+	}
+	drawer.DrawString(text)
+}
+
+func drawGraphLine(img *image.RGBA, x0 int, y0 int, x1 int, y1 int, thickness int, c color.Color) {
+	dx := int(math.Abs(float64(x1 - x0)))
+	dy := -int(math.Abs(float64(y1 - y0)))
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	e := dx + dy
+	for {
+		fillGraphRect(img, image.Rect(x0-thickness/2, y0-thickness/2, x0-thickness/2+thickness, y0-thickness/2+thickness), c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+func DrawScoreGraph(scoreData ScoreResult, options GraphOptions) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, options.Width, options.Height))
+	fillGraphRect(img, img.Bounds(), graphBackgroundColor)
+
+	plot := image.Rect(graphMarginLeft, graphMarginTop, options.Width-graphMarginRight, options.Height-graphMarginBottom)
+	var densityPlot image.Rectangle
+	if options.Density {
+		densityHeight := int(float64(plot.Dy()) * graphDensityRatio)
+		densityPlot = image.Rect(plot.Min.X, plot.Max.Y-densityHeight, plot.Max.X, plot.Max.Y)
+		plot.Max.Y = densityPlot.Min.Y - graphMarginBottom/2
+	}
+	if plot.Dx() <= 0 || plot.Dy() <= 0 {
+		return img
+	}
+
+	frames := scoreData.Frames
+	startTime, endTime := 0.0, 1.0
+	if len(frames) > 0 {
+		startTime = math.Min(0, frames[0].Time)
+		endTime = math.Max(endTime, frames[len(frames)-1].Time)
+	}
+	borders := options.RankTable.Borders(options.Rating)
+	maxScore := borders.Max
+	if len(frames) > 0 {
+		maxScore = math.Max(maxScore, float64(frames[len(frames)-1].Score))
+	}
+	maxScore *= 1.05
+
+	timeToX := func(time float64) int {
+		return plot.Min.X + int((time-startTime)/(endTime-startTime)*float64(plot.Dx()-1))
+	}
+	scoreToY := func(score float64) int {
+		return plot.Max.Y - 1 - int(score/maxScore*float64(plot.Dy()-1))
+	}
+
+	// 時間の目盛り
+	timeStep := graphTimeSteps[len(graphTimeSteps)-1]
+	for _, step := range graphTimeSteps {
+		if (endTime-startTime)/step <= 10 {
+			timeStep = step
+			break
+		}
+	}
+	for time := 0.0; time <= endTime; time += timeStep {
+		x := timeToX(time)
+		fillGraphRect(img, image.Rect(x, plot.Min.Y, x+1, plot.Max.Y), graphGridColor)
+		fillGraphRect(img, image.Rect(x, plot.Max.Y, x+1, plot.Max.Y+4), graphAxisColor)
+		if options.Density {
+			fillGraphRect(img, image.Rect(x, densityPlot.Max.Y, x+1, densityPlot.Max.Y+4), graphAxisColor)
+		}
+		drawGraphText(img, x-14, options.Height-8, fmt.Sprintf("%d:%02d", int(time)/60, int(time)%60), graphTextColor)
+	}
+
+	// ランクのボーダー
+	for _, border := range []struct {
+		rank  string
+		score float64
+	}{
+		{"c", borders.C},
+		{"b", borders.B},
+		{"a", borders.A},
+		{"s", borders.S},
+	} {
+		y := scoreToY(border.score)
+		borderColor := RANK_BORDER_COLORS[border.rank]
+		for x := plot.Min.X; x < plot.Max.X; x += 8 {
+			fillGraphRect(img, image.Rect(x, y, x+4, y+1), borderColor)
+		}
+		drawGraphText(img, 4, y+4, fmt.Sprintf("%s %d", strings.ToUpper(border.rank), int(border.score)), borderColor)
+	}
+
+	// スコアは次のフレームまで変わらないので、階段状に描く
+	for i := 1; i < len(frames); i++ {
+		previousX, previousY := timeToX(frames[i-1].Time), scoreToY(float64(frames[i-1].Score))
+		x, y := timeToX(frames[i].Time), scoreToY(float64(frames[i].Score))
+		drawGraphLine(img, previousX, previousY, x, previousY, 2, graphScoreColor)
+		drawGraphLine(img, x, previousY, x, y, 2, graphScoreColor)
+	}
+
+	fillGraphRect(img, image.Rect(plot.Min.X, plot.Min.Y, plot.Min.X+1, plot.Max.Y), graphAxisColor)
+	fillGraphRect(img, image.Rect(plot.Min.X, plot.Max.Y-1, plot.Max.X, plot.Max.Y), graphAxisColor)
+
+	if options.Density {
+		density := CalculateStats(sonolus.LevelInfo{}, scoreData).Density
+		maxDensity := 1
+		for _, count := range density {
+			if count > maxDensity {
+				maxDensity = count
+			}
+		}
+		for second, count := range density {
+			left, right := timeToX(float64(second)), timeToX(float64(second+1))
+			height := int(float64(count) / float64(maxDensity) * float64(densityPlot.Dy()))
+			fillGraphRect(img, image.Rect(left, densityPlot.Max.Y-height, int(math.Max(float64(left+1), float64(right-1))), densityPlot.Max.Y), graphDensityColor)
+		}
+		fillGraphRect(img, image.Rect(densityPlot.Min.X, densityPlot.Min.Y, densityPlot.Min.X+1, densityPlot.Max.Y), graphAxisColor)
+		fillGraphRect(img, image.Rect(densityPlot.Min.X, densityPlot.Max.Y-1, densityPlot.Max.X, densityPlot.Max.Y), graphAxisColor)
+		drawGraphText(img, 4, densityPlot.Min.Y+10, fmt.Sprintf("%d/s", maxDensity), graphTextColor)
+	}
+
+	return img
+}
+
+func WriteScoreGraphFile(scoreData ScoreResult, path string, options GraphOptions) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return NewError(ErrorFilesystem, err, "error.createFile", err)
+	}
+
+	err = png.Encode(file, DrawScoreGraph(scoreData, options))
+	if err != nil {
+		file.Close()
+		return NewError(ErrorEncoding, err, "error.encodeImage", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return NewError(ErrorFilesystem, err, "error.writeFile", err)
+	}
+	return nil
+}
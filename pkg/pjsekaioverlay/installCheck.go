@@ -5,6 +5,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	wapi "github.com/iamacarpet/go-win64api"
@@ -17,7 +18,8 @@ import (
 //go:embed sekai.obj
 var sekaiObj []byte
 
-func TryInstallObject() bool {
+// 起動しているAviUtlの拡張編集のディレクトリを返す。見つからない場合は空文字列。
+func FindExeditRoot() string {
 	processes, _ := wapi.ProcessList()
 	var aviutlProcess *so.Process
 	for _, process := range processes {
@@ -27,28 +29,76 @@ func TryInstallObject() bool {
 		}
 	}
 	if aviutlProcess == nil {
-		return false
+		return ""
 	}
 	var aviutlPath string
 	aviutlPath = filepath.Dir(aviutlProcess.Fullpath)
-	var exeditRoot string
 	if _, err := os.Stat(filepath.Join(aviutlPath, "exedit.auf")); err == nil {
-		exeditRoot = filepath.Join(aviutlPath)
+		return filepath.Join(aviutlPath)
 	} else if _, err := os.Stat(filepath.Join(aviutlPath, "Plugins", "exedit.auf")); err == nil {
-		exeditRoot = filepath.Join(aviutlPath, "Plugins")
-	} else {
-		return false
+		return filepath.Join(aviutlPath, "Plugins")
 	}
+	return ""
+}
 
-	os.MkdirAll(filepath.Join(exeditRoot, "script"), 0755)
+// インストール先のオブジェクトファイルのパス。
+func ObjectPath(exeditRoot string) string {
+	return filepath.Join(exeditRoot, "script", "@pjsekai-overlay.obj")
+}
+
+// インストールされているオブジェクトの先頭の「--version: 」などの値を返す。
+func installedObjectHeader(exeditRoot string) (map[string]string, error) {
+	sekaiObjFile, err := os.Open(ObjectPath(exeditRoot))
+	if err != nil {
+		return nil, err
+	}
+	defer sekaiObjFile.Close()
+	existingSekaiObj, err := io.ReadAll(transform.NewReader(sekaiObjFile, japanese.ShiftJIS.NewDecoder()))
+	if err != nil {
+		return nil, err
+	}
+	header := map[string]string{}
+	for _, line := range strings.Split(string(existingSekaiObj), "\n") {
+		name, value, ok := strings.Cut(strings.TrimPrefix(line, "--"), ":")
+		if !strings.HasPrefix(line, "--") || !ok {
+			break
+		}
+		header[name] = strings.TrimSpace(value)
+	}
+	return header, nil
+}
+
+// インストールされているオブジェクトのバージョンを返す。
+func InstalledObjectVersion(exeditRoot string) (string, error) {
+	header, err := installedObjectHeader(exeditRoot)
+	if err != nil {
+		return "", err
+	}
+	return header["version"], nil
+}
+
+// sekai.objの中の文字列リテラルに埋め込むメッセージ。
+func objectMessageReplacements() []string {
+	escaper := strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "\n", "\\n")
+	replacements := []string{}
+	for key := range CATALOGS[DefaultLocale] {
+		if strings.HasPrefix(key, "object.") {
+			replacements = append(replacements, "{text:"+key+"}", escaper.Replace(T(key)))
+		}
+	}
+	return replacements
+}
+
+func TryInstallObject() bool {
+	exeditRoot := FindExeditRoot()
+	if exeditRoot == "" {
+		return false
+	}
 
-	var sekaiObjPath = filepath.Join(exeditRoot, "script", "@pjsekai-overlay.obj")
-	if _, err := os.Stat(sekaiObjPath); err == nil {
-		var sekaiObjFile, _ = os.OpenFile(sekaiObjPath, os.O_RDONLY, 0755)
-		defer sekaiObjFile.Close()
-		var sekaiObjDecoder = japanese.ShiftJIS.NewDecoder()
-		var existingSekaiObj, _ = io.ReadAll(transform.NewReader(sekaiObjFile, sekaiObjDecoder))
-		if strings.Contains(string(existingSekaiObj), "--version: "+Version) && Version != "0.0.0" {
+	var sekaiObjPath = ObjectPath(exeditRoot)
+	if header, err := installedObjectHeader(exeditRoot); err == nil {
+		// 言語が変わった場合はメッセージを入れ直す
+		if header["version"] == Version && header["lang"] == Locale() && Version != "0.0.0" {
 			return false
 		}
 	}
@@ -64,11 +114,15 @@ func TryInstallObject() bool {
 
 	var sekaiObjWriter = transform.NewWriter(sekaiObjFile, japanese.ShiftJIS.NewEncoder())
 
+	// メッセージを埋め込んでから、改行とバージョンを置き換える
+	localizedSekaiObj := strings.NewReplacer(objectMessageReplacements()...).Replace(string(sekaiObj))
 	strings.NewReader(strings.NewReplacer(
 		"\r\n", "\r\n",
 		"\r", "\r\n",
 		"\n", "\r\n",
 		"{version}", Version,
-	).Replace(string(sekaiObj))).WriteTo(sekaiObjWriter)
+		"{pedFormatVersion}", strconv.Itoa(PedFormatVersion),
+		"{lang}", Locale(),
+	).Replace(localizedSekaiObj)).WriteTo(sekaiObjWriter)
 	return true
 }
@@ -0,0 +1,106 @@
+package pjsekaioverlay
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type Judgement int
+
+const (
+	JudgementPerfect Judgement = iota
+	JudgementGreat
+	JudgementGood
+	JudgementBad
+	JudgementMiss
+)
+
+var JUDGEMENT_NAMES = map[Judgement]string{
+	JudgementPerfect: "perfect",
+	JudgementGreat:   "great",
+	JudgementGood:    "good",
+	JudgementBad:     "bad",
+	JudgementMiss:    "miss",
+}
+
+// 判定毎のスコアの倍率。Great・Goodの値は近似値で、--judge-tableで上書きできる。
+var JUDGE_WEIGHT_MAP = map[Judgement]float64{
+	JudgementPerfect: 1,
+	JudgementGreat:   0.8,
+	JudgementGood:    0.5,
+	JudgementBad:     0,
+	JudgementMiss:    0,
+}
+
+func (judgement Judgement) String() string {
+	return JUDGEMENT_NAMES[judgement]
+}
+
+func ParseJudgement(name string) (Judgement, error) {
+	for judgement, judgementName := range JUDGEMENT_NAMES {
+		if strings.EqualFold(name, judgementName) {
+			return judgement, nil
+		}
+	}
+	return JudgementPerfect, NewError(ErrorDecode, nil, "error.unknownJudgement", name)
+}
+
+// 判定ファイルは1行に「ノーツ番号 判定」を書く形式。ノーツ番号は時間順で1から数え、
+// 「100-120 miss」のように範囲も指定できる。指定の無いノーツはPerfect扱い。
+// ダメージノーツは「miss」で被弾したことを表す。
+func ParseJudgements(reader io.Reader) (map[int]Judgement, error) {
+	judgements := map[int]Judgement{}
+	scanner := bufio.NewScanner(reader)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+		if commentIndex := strings.Index(line, "#"); commentIndex >= 0 {
+			line = line[:commentIndex]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, NewError(ErrorDecode, nil, "error.judgementLine", lineNumber)
+		}
+		judgement, err := ParseJudgement(fields[1])
+		if err != nil {
+			return nil, NewError(ErrorDecode, err, "error.judgementLineCause", lineNumber, err)
+		}
+		start, end, found := strings.Cut(fields[0], "-")
+		if !found {
+			end = start
+		}
+		startIndex, err := strconv.Atoi(start)
+		if err != nil || startIndex < 1 {
+			return nil, NewError(ErrorDecode, nil, "error.judgementNoteIndex", lineNumber)
+		}
+		endIndex, err := strconv.Atoi(end)
+		if err != nil || endIndex < startIndex {
+			return nil, NewError(ErrorDecode, nil, "error.judgementNoteIndex", lineNumber)
+		}
+		for i := startIndex; i <= endIndex; i++ {
+			judgements[i] = judgement
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, NewError(ErrorFilesystem, err, "error.judgementRead", err)
+	}
+
+	return judgements, nil
+}
+
+func LoadJudgements(path string) (map[int]Judgement, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, NewError(ErrorFilesystem, err, "error.judgementOpen", err)
+	}
+	defer file.Close()
+
+	return ParseJudgements(file)
+}
@@ -0,0 +1,94 @@
+package pjsekaioverlay
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// 判定毎のスコアの倍率とライフの増減。
+// ゲームの正確な値は公開されていないので、既定の値（JUDGE_WEIGHT_MAPとDEFAULT_LIFE_TABLE）は近似値。
+// 合わない場合はJSONで上書きする。
+type JudgeTable struct {
+	Weights map[Judgement]float64
+	Life    LifeTable
+}
+
+var DEFAULT_JUDGE_TABLE = JudgeTable{
+	Weights: JUDGE_WEIGHT_MAP,
+	Life:    DEFAULT_LIFE_TABLE,
+}
+
+// 判定のテーブルのJSON。書かなかった項目は既定の値のまま。
+//
+//	{
+//	  "weights": { "great": 0.8, "good": 0.5 },
+//	  "life": {
+//	    "initial": 1000,
+//	    "max": 1000,
+//	    "note": { "bad": -50, "miss": -80 },
+//	    "tick": { "miss": -40 },
+//	    "damage": { "miss": -50 }
+//	  }
+//	}
+type judgeTableFile struct {
+	Weights map[string]float64 `json:"weights"`
+	Life    struct {
+		Initial *int           `json:"initial"`
+		Max     *int           `json:"max"`
+		Note    map[string]int `json:"note"`
+		Tick    map[string]int `json:"tick"`
+		Damage  map[string]int `json:"damage"`
+	} `json:"life"`
+}
+
+// 判定の名前をキーにした値でbaseを上書きしたものを返す。baseは変更しない。
+func overrideJudgementMap[V any](base map[Judgement]V, values map[string]V) (map[Judgement]V, error) {
+	overridden := make(map[Judgement]V, len(base)+len(values))
+	for judgement, value := range base {
+		overridden[judgement] = value
+	}
+	for name, value := range values {
+		judgement, err := ParseJudgement(name)
+		if err != nil {
+			return nil, NewError(ErrorDecode, err, "error.judgeTableRead", err)
+		}
+		overridden[judgement] = value
+	}
+	return overridden, nil
+}
+
+func LoadJudgeTable(path string) (JudgeTable, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return JudgeTable{}, NewError(ErrorFilesystem, err, "error.judgeTableOpen", err)
+	}
+	var file judgeTableFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return JudgeTable{}, NewError(ErrorDecode, err, "error.judgeTableRead", err)
+	}
+
+	table := DEFAULT_JUDGE_TABLE
+	if table.Weights, err = overrideJudgementMap(table.Weights, file.Weights); err != nil {
+		return JudgeTable{}, err
+	}
+	if file.Life.Initial != nil {
+		table.Life.Initial = *file.Life.Initial
+	}
+	if file.Life.Max != nil {
+		table.Life.Max = *file.Life.Max
+	}
+	if table.Life.Note, err = overrideJudgementMap(table.Life.Note, file.Life.Note); err != nil {
+		return JudgeTable{}, err
+	}
+	if table.Life.Tick, err = overrideJudgementMap(table.Life.Tick, file.Life.Tick); err != nil {
+		return JudgeTable{}, err
+	}
+	if table.Life.Damage, err = overrideJudgementMap(table.Life.Damage, file.Life.Damage); err != nil {
+		return JudgeTable{}, err
+	}
+	if table.Life.Max <= 0 || table.Life.Initial < 0 || table.Life.Initial > table.Life.Max {
+		return JudgeTable{}, NewError(ErrorDecode, nil, "error.judgeTableLife", table.Life.Initial, table.Life.Max)
+	}
+
+	return table, nil
+}
@@ -0,0 +1,54 @@
+package pjsekaioverlay
+
+type LifeTable struct {
+	Initial int
+	Max     int
+
+	// 通常のノーツ
+	Note map[Judgement]int
+	// 中継点・トレースなど、重みが1未満のノーツ
+	Tick map[Judgement]int
+	// ダメージノーツ（Missが被弾）
+	Damage map[Judgement]int
+}
+
+// ライフの増減は近似値で、--judge-tableで上書きできる。
+var DEFAULT_LIFE_TABLE = LifeTable{
+	Initial: 1000,
+	Max:     1000,
+
+	Note: map[Judgement]int{
+		JudgementBad:  -50,
+		JudgementMiss: -80,
+	},
+	Tick: map[Judgement]int{
+		JudgementMiss: -40,
+	},
+	Damage: map[Judgement]int{
+		JudgementMiss: -50,
+	},
+}
+
+func isDamageNote(archetype string) bool {
+	return archetype == "DamageNote"
+}
+
+func (table LifeTable) Change(archetype string, weight float64, judgement Judgement) int {
+	if isDamageNote(archetype) {
+		return table.Damage[judgement]
+	}
+	if weight < 1 {
+		return table.Tick[judgement]
+	}
+	return table.Note[judgement]
+}
+
+func (table LifeTable) Apply(life int, change int) int {
+	life += change
+	if life < 0 {
+		life = 0
+	} else if life > table.Max {
+		life = table.Max
+	}
+	return life
+}
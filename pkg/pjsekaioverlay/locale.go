@@ -0,0 +1,79 @@
+package pjsekaioverlay
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// メッセージのカタログ。値はfmt.Sprintfの書式。
+type Catalog map[string]string
+
+const (
+	DefaultLocale = "ja"
+	LocaleEnv     = "PJSEKAI_OVERLAY_LANG"
+)
+
+var CATALOGS = map[string]Catalog{
+	"ja": catalogJa,
+	"en": catalogEn,
+}
+
+var currentLocale = DefaultLocale
+
+// 「en_US.UTF-8」「en-US」などを「en」にする。
+func NormalizeLocale(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if index := strings.IndexAny(locale, "_-.@"); index >= 0 {
+		locale = locale[:index]
+	}
+	return locale
+}
+
+func SetLocale(locale string) error {
+	normalized := NormalizeLocale(locale)
+	if _, ok := CATALOGS[normalized]; !ok {
+		return Errorf("error.unknownLocale", locale)
+	}
+	currentLocale = normalized
+	return nil
+}
+
+func Locale() string {
+	return currentLocale
+}
+
+// 環境変数で指定された言語。LC_ALLなどは対応している言語の場合だけ使う。
+func LocalesFromEnv() []string {
+	locales := []string{}
+	for _, name := range []string{LocaleEnv, "LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			locales = append(locales, value)
+		}
+	}
+	return locales
+}
+
+func lookupMessage(key string) string {
+	if message, ok := CATALOGS[currentLocale][key]; ok {
+		return message
+	}
+	if message, ok := CATALOGS[DefaultLocale][key]; ok {
+		return message
+	}
+	return key
+}
+
+// 現在の言語のメッセージを返す。argsが無い場合は書式をそのまま返す。
+func T(key string, args ...any) string {
+	message := lookupMessage(key)
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// 現在の言語のメッセージでエラーを作る。%wも使える。
+func Errorf(key string, args ...any) error {
+	return fmt.Errorf(lookupMessage(key), args...)
+}
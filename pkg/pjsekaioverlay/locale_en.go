@@ -0,0 +1,312 @@
+package pjsekaioverlay
+
+var catalogEn = Catalog{
+	// 共通
+	"common.success":        "Done",
+	"common.failure":        "Failed: %s",
+	"common.chartId":        "Chart ID: %s",
+	"common.outDir":         "Output directory: %s",
+	"common.assets":         "Assets: %s",
+	"common.unknownCommand": "Unknown command: %s",
+	"common.pressKey":       "\nPress any key to exit...",
+
+	// 使い方
+	"usage.generate":    "Usage: pjsekai-overlay generate [chart ID] [options]",
+	"usage.fetch":       "Usage: pjsekai-overlay fetch [chart ID] [options]",
+	"usage.score":       "Usage: pjsekai-overlay score [chart ID] [options]",
+	"usage.exo":         "Usage: pjsekai-overlay exo [chart ID] [options]",
+	"usage.stats":       "Usage: pjsekai-overlay stats [chart ID] [options]",
+	"usage.doctor":      "Usage: pjsekai-overlay doctor [options]",
+	"usage.relocate":    "Usage: pjsekai-overlay relocate [output directory] [options]",
+	"usage.pedBuild":    "Usage: pjsekai-overlay ped build [chart ID] [options]",
+	"usage.pedInspect":  "Usage: pjsekai-overlay ped inspect [ped file] [options]",
+	"usage.pedValidate": "Usage: pjsekai-overlay ped validate [ped file] [options]",
+	"usage.pedDiff":     "Usage: pjsekai-overlay ped diff [ped file] [ped file] [options]",
+	"usage.update":      "Usage: pjsekai-overlay update [options]",
+	"usage.regen":       "Usage: pjsekai-overlay regen [output directory] [options]",
+
+	// オプション
+	"flag.outDir":          "Output directory. {title}, {author}, {source}, {difficulty}, {rating}, {date}, {slug} and {chartId} (_chartId_) are replaced with the chart info.",
+	"flag.onExists":        "What to do when the output directory already exists. (ask / overwrite / suffix: append \"-2\" etc.)",
+	"flag.assets":          "Assets directory. Defaults to assets in the output directory, or assets next to the exe.",
+	"flag.noAviutlInstall": "Skip installing the AviUtl object.",
+	"flag.timeline":        "Comma-separated formats to export the score timeline in. (json / csv)",
+	"flag.timelineFps":     "Frame rate used to calculate frame numbers in the score timeline.",
+	"flag.graph":           "Output a graph of the score over time (graph.png).",
+	"flag.graphDensity":    "Add a note density histogram to the graph.",
+	"flag.scoreBreakdown":  "Show the score breakdown.",
+	"flag.teamPower":       "Team power.",
+	"flag.weights":         "Note weights file. Defaults to weights.json next to the exe.",
+	"flag.judgements":      "Judgements file. Notes without a judgement are treated as Perfect.",
+	"flag.judgeTable":      "JSON file overriding the score multiplier and life change of each judgement. The defaults (Great 0.8, Good 0.5, etc.) are approximations.",
+	"flag.skills":          "Comma-separated skill activation times (seconds).",
+	"flag.skillDuration":   "Skill duration (seconds).",
+	"flag.skillBonus":      "Skill score bonus (%).",
+	"flag.apCombo":         "Enable the AP combo display.",
+	"flag.rankTable":       "Rank border table. (solo / append / path to a JSON file)",
+	"flag.fever":           "Fever window (seconds) in the form \"start-end\".",
+	"flag.frameTableFps":   "Frame rate of the per-frame table written to the ped file. 0 disables it.",
+	"flag.portable":        "Copy the assets into the output directory and write a relative path to the ped file.",
+	"flag.deterministic":   "Always generate the same ped and exo files from the same chart and options.",
+	"flag.timestamp":       "Timestamp written to the ped file. (now / source (SOURCE_DATE_EPOCH) / unix time)",
+	"flag.difficulty":      "Difficulty shown in the exo file.",
+	"flag.pedDifficulty":   "Difficulty used for {difficulty} in the output directory.",
+	"flag.theme":           "Difficulty background. (master / append) Both are included in the exo file if omitted.",
+	"flag.resolution":      "Resolution of the exo file in the form \"1920x1080\". Only 16:9 resolutions are supported.",
+	"flag.profile":         "Profile in the config file.",
+	"flag.noConfig":        "Do not load config files.",
+	"flag.yes":             "Use defaults instead of asking for unspecified options.",
+	"flag.noInput":         "Never ask for input. Fails if a required value is missing.",
+	"flag.offline":         "Do not check connections to the servers.",
+	"flag.events":          "Number of events to show at the start and end.",
+	"flag.validateRating":  "Level of the chart. If specified, also checks that the ranks and bar widths match the scores.",
+	"flag.output":          "File to export the score timeline to. (.json / .csv)",
+	"flag.format":          "Output format. (table / json / csv)",
+	"flag.json":            "Write progress and results to stdout as JSON Lines.",
+	"flag.noUpdateCheck":   "Do not check for a new version.",
+	"flag.updateCheck":     "Only check whether a new version is available.",
+	"flag.updateForce":     "Download again even if already up to date.",
+	"flag.refresh":         "Fetch the chart info, cover and background again instead of using the cache.",
+
+	// 入力
+	"prompt.chartId":         "Enter the chart ID including its prefix.",
+	"prompt.teamPower":       "Enter the team power.",
+	"prompt.apCombo":         "Enable the AP combo display?",
+	"prompt.overwriteOutDir": "%s already exists. Overwrite it? (If not, output goes to a directory with \"-2\" etc. appended)",
+
+	// 進捗
+	"progress.fetchChart":     "Fetching chart from %s... ",
+	"progress.cover":          "Downloading cover... ",
+	"progress.background":     "Downloading background... ",
+	"progress.parseChart":     "Parsing chart... ",
+	"progress.judgements":     "Loading judgements file... ",
+	"progress.score":          "Calculating score... ",
+	"progress.copyAssets":     "Copying assets... ",
+	"progress.ped":            "Generating ped file... ",
+	"progress.exo":            "Generating exo file... ",
+	"progress.timeline":       "Exporting score timeline... ",
+	"progress.timelineFormat": "Exporting score timeline (%s)... ",
+	"progress.graph":          "Drawing graph... ",
+	"progress.relocateExo":    "Rewriting exo file... ",
+	"progress.relocatePed":    "Rewriting ped file... ",
+	"progress.eta":            "%s left",
+	"progress.checkUpdate":    "Checking the latest version... ",
+	"progress.downloadUpdate": "Downloading %s... ",
+	"progress.verifyUpdate":   "Verifying the download... ",
+	"progress.applyUpdate":    "Replacing files... ",
+	"progress.manifest":       "Writing manifest... ",
+
+	// generate
+	"generate.done":            "\nAll done. Check the terms in the README, then import the exo file into AviUtl.",
+	"generate.objectInstalled": "Installed the AviUtl object.",
+
+	// install
+	"install.aviutlNotFound": "AviUtl was not found. Start AviUtl and try again.",
+	"install.upToDate":       "The AviUtl object is already up to date.",
+
+	// アップデート
+	"update.available": "A new version is available: v%s -> v%s",
+	"update.download":  "Download: %s",
+	"update.command":   "Run the update command to update.",
+	"update.upToDate":  "Already up to date (v%s).",
+	"update.updated":   "Updated to v%s.",
+
+	// regen
+	"regen.version": "Regenerating a directory generated by v%s with v%s.",
+	"regen.done":    "\nRegenerated the outputs.",
+
+	// 警告
+	"warning.unknownArchetypes": "Warning: there are unknown archetypes. They are not included in the score.",
+	"warning.addWeights":        "  Add weights for them to the engine \"%s\" in the weights file (weights.json).",
+	"warning.untimedNotes":      "Warning: could not get the beat of %d notes. They are not included in the score.",
+	"warning.unknownLocale":     "Warning: unsupported language: %s",
+
+	// スコアの内訳
+	"breakdown.base":     "  Base:   %s\n",
+	"breakdown.level":    "  Level:  %s (x%.3f)\n",
+	"breakdown.combo":    "  Combo:  %s (up to x%.2f)\n",
+	"breakdown.skill":    "  Skill:  %s (up to x%.2f)\n",
+	"breakdown.total":    "  Total:  %s\n",
+	"breakdown.mismatch": "  The final score (%d) does not match the breakdown total.",
+	"score.rank":         "Rank: %s",
+
+	// doctor
+	"doctor.assets":             "Assets",
+	"doctor.weights":            "Weights",
+	"doctor.object":             "Object",
+	"doctor.cache":              "Cache",
+	"doctor.missingAssets":      "%s is missing %s and possibly more. Extract the zip again.",
+	"doctor.aviutlNotRunning":   "No running AviUtl was found. Start AviUtl to check the object.",
+	"doctor.objectNotInstalled": "Not installed. Install it with the install command.",
+	"doctor.objectOutdated":     "Version mismatch (%s). Reinstall it with the install command.",
+	"doctor.line":               "%s %s: %s",
+	"doctor.serverError":        "The server returned an error. (%d)",
+
+	// cache
+	"cache.header":  "Kind\tName\tSize\tModified",
+	"cache.cleared": "Cleared the cache.",
+
+	// ped inspect / validate / diff
+	"inspect.formatVersion":  "Format version: %d\n",
+	"inspect.version":        "Version:        %s\n",
+	"inspect.timestamp":      "Generated at:   %s\n",
+	"inspect.assets":         "Assets:         %s\n",
+	"inspect.ap":             "AP display:     %t\n",
+	"inspect.scores":         "Scores:         %d\n",
+	"inspect.duration":       "Duration:       %.3fs\n",
+	"inspect.finalScore":     "Final score:    %s (%s)\n",
+	"inspect.maxCombo":       "Max combo:      %d\n",
+	"inspect.finalLife":      "Final life:     %d\n",
+	"inspect.frameTable":     "Frame table:    %gfps, %d runs\n",
+	"inspect.frameTableNone": "Frame table:    none\n",
+	"inspect.events":         "Events:",
+	"inspect.firstEvents":    "First events:",
+	"inspect.lastEvents":     "Last events:",
+	"validate.ok":            "No problems found.",
+	"validate.problems":      "Found %d problems.",
+	"diff.same":              "No differences.",
+	"diff.count":             "%d differences.",
+
+	// pedファイルの問題
+	"problem.formatVersion":   "Format version is %[2]d instead of %[1]d.",
+	"problem.noVersion":       "Version is missing.",
+	"problem.version":         "Version is %[2]s instead of %[1]s.",
+	"problem.noAssets":        "Assets path is missing.",
+	"problem.noScores":        "There are no scores.",
+	"problem.barWidth":        "Bar width (%f) is outside 0-1.",
+	"problem.life":            "Life (%d) is outside 0-%d.",
+	"problem.firstDelta":      "Score delta (%d) does not match the score (%d).",
+	"problem.scoreTime":       "Time (%f) is not after the previous time (%f).",
+	"problem.delta":           "Score delta (%d) does not match the difference from the previous score (%d).",
+	"problem.scoreDecreased":  "Score (%d) is lower than the previous score (%d).",
+	"problem.rankDecreased":   "Rank dropped from %s to %s although the score did not decrease.",
+	"problem.barDecreased":    "Bar width decreased although the score increased.",
+	"problem.rank":            "The rank should be %s but is %s. (score: %d)",
+	"problem.rankBarWidth":    "The bar width should be %f but is %f.",
+	"problem.eventTime":       "Time (%f) is before the previous time (%f).",
+	"problem.frame":           "Frame (%d) is not after the previous frame.",
+	"problem.frameScoreIndex": "s index (%d) exceeds the number of s records (%d).",
+
+	// stats
+	"stats.title":           "Title\t%s\n",
+	"stats.author":          "Chart author\t%s\n",
+	"stats.rating":          "Level\t%d\n",
+	"stats.notes":           "Notes\t%d\n",
+	"stats.comboNotes":      "Combo notes\t%d\n",
+	"stats.weightedNotes":   "Weighted notes\t%.1f\n",
+	"stats.duration":        "Duration\t%s\n",
+	"stats.maxCombo":        "Max combo\t%d\n",
+	"stats.peakNps":         "Peak NPS\t%d\n",
+	"stats.finalScore":      "Final score\t%d\n",
+	"stats.archetypeHeader": "Archetype\tCount\tWeight",
+	"stats.densityHeader":   "Second\tNotes",
+	"stats.unknownFormat":   "Unknown output format: %s",
+
+	// エラー
+	"error.unknownLocale":         "Unsupported language: %s",
+	"error.unknownSource":         "Could not determine the chart's server. Enter a valid chart ID including its prefix.",
+	"error.unsupportedEngine":     "This engine is not supported. (version %d)",
+	"error.noChartId":             "No chart ID was given. %s",
+	"error.noInput":               "Cannot ask for input. Specify it on the command line.",
+	"error.connect":               "Could not connect to the server.",
+	"error.connectCause":          "Could not connect to the server. (%s)",
+	"error.chartNotFound":         "Chart not found.",
+	"error.chartRead":             "Failed to read the chart info. (%s)",
+	"error.parseUrl":              "Failed to parse the URL. (%s)",
+	"error.levelDataNotFound":     "Chart data not found. (%d)",
+	"error.levelDataDownload":     "Failed to download the chart data. (%s)",
+	"error.levelDataRead":         "Failed to read the chart data. (%s)",
+	"error.coverNotFound":         "Cover not found. (%d)",
+	"error.coverRead":             "Failed to read the cover. (%s)",
+	"error.backgroundNotFound":    "Background not found. (%d)",
+	"error.createFile":            "Failed to create the file. (%s)",
+	"error.writeFile":             "Failed to write the file. (%w)",
+	"error.encode":                "Failed to encode. (%w)",
+	"error.decode":                "Failed to decode. (%w)",
+	"error.encodeImage":           "Failed to encode the image. (%s)",
+	"error.cacheDir":              "Could not get the cache directory. (%s)",
+	"error.cacheWrite":            "Failed to write the cache. (%s)",
+	"error.cacheRead":             "Failed to read the cache. (%s)",
+	"error.cacheClear":            "Failed to clear the cache. (%s)",
+	"error.configValue":           "invalid value for %s",
+	"error.configProfile":         "profile \"%s\": %w",
+	"error.configSources":         "sources need id, host and prefix",
+	"error.configOpen":            "Could not open the config file. (%s)",
+	"error.configRead":            "Failed to load the config file (%s). (%s)",
+	"error.configOption":          "Invalid %s in the config file. (%s)",
+	"error.profileNotFound":       "Profile \"%s\" not found.",
+	"error.invalidSkillTime":      "Invalid skill activation time: %s",
+	"error.invalidTimeWindow":     "Invalid time window: %s",
+	"error.invalidResolution":     "Invalid resolution: %s",
+	"error.resolutionAspect":      "Resolution %dx%d is not supported. Specify a 16:9 resolution such as 1920x1080.",
+	"error.invalidTheme":          "Invalid theme: %s",
+	"error.exoPlaceholder":        "Failed to generate the exo file (%s not found)",
+	"error.tooLongText":           "Text is too long. (%d characters, up to %d)",
+	"error.unknownPlaceholder":    "Unknown placeholder in the output directory: %s",
+	"error.invalidOnExists":       "Invalid --on-exists: %s",
+	"error.manifestOpen":          "Failed to open the manifest. (%s)",
+	"error.manifestRead":          "Failed to read the manifest. (%s)",
+	"error.manifestFormat":        "Unsupported manifest format (%d).",
+	"error.manifestNoChartId":     "The manifest has no chart ID.",
+	"error.manifestOption":        "Invalid %s in the manifest. (%s)",
+	"error.manifestHash":          "Failed to hash %s. (%s)",
+	"error.invalidVersion":        "Invalid version: %s",
+	"error.offline":               "Not connected to the internet.",
+	"error.downloadStatus":        "Download failed. (%d)",
+	"error.updateAssetNotFound":   "The release has no %s.",
+	"error.updateChecksumRead":    "Failed to read the checksum file. (%s)",
+	"error.updateChecksumMissing": "The checksum file has no entry for %s.",
+	"error.updateChecksum":        "The checksum of the download does not match.",
+	"error.updateSize":            "The download is %d bytes instead of %d.",
+	"error.updateZip":             "The downloaded zip is broken. (%s)",
+	"error.updateZipPath":         "The zip contains an invalid path: %s",
+	"error.updateMissingFile":     "The downloaded zip has no %s.",
+	"error.updateReplace":         "Failed to replace files. (%s)",
+	"error.unknownJudgement":      "Unknown judgement: %s",
+	"error.judgementLine":         "Line %d of the judgements file is invalid.",
+	"error.judgementLineCause":    "Line %d of the judgements file is invalid. (%s)",
+	"error.judgementNoteIndex":    "Line %d of the judgements file has an invalid note index.",
+	"error.judgementRead":         "Failed to read the judgements file. (%s)",
+	"error.judgementOpen":         "Could not open the judgements file. (%s)",
+	"error.pedLine":               "Line %d of the ped file is invalid.",
+	"error.pedRead":               "Failed to read the ped file. (%s)",
+	"error.pedScore":              "Line %d of the ped file has an invalid score.",
+	"error.pedScoreCause":         "Line %d of the ped file has an invalid score. (%s)",
+	"error.pedRank":               "Line %d of the ped file has an invalid rank. (%s)",
+	"error.pedEvent":              "Line %d of the ped file has an invalid event.",
+	"error.pedEventCause":         "Line %d of the ped file has an invalid event. (%s)",
+	"error.pedFrameTable":         "Line %d of the ped file has an invalid frame table.",
+	"error.pedFormatVersion":      "The ped file has an invalid format version. (%s)",
+	"error.pedFormatNotFirst":     "The ped file has the format version on line %d. The format version must be on the first line.",
+	"error.pedUnsupportedFormat":  "Unsupported ped file format (%d).",
+	"error.pedFrameRate":          "Line %d of the ped file has an invalid frame rate.",
+	"error.pedNoFrameRate":        "The ped file has no frame rate before line %d.",
+	"error.pedOpen":               "Could not open the ped file. (%s)",
+	"error.pedNoAssets":           "The ped file has no assets path.",
+	"error.copyAssets":            "Failed to copy the assets. (%w)",
+	"error.exoOpen":               "Could not open the exo file. (%s)",
+	"error.exoNoPed":              "The exo file has no ped file path.",
+	"error.rankTableOpen":         "Could not open the rank table. (%s)",
+	"error.rankTableRead":         "Failed to read the rank table. (%s)",
+	"error.rankTableBarWidth":     "The rank table has invalid bar widths.",
+	"error.rankTableRating":       "The rank table has a minimum level (%d) greater than its maximum level (%d).",
+	"error.rankTableBorders":      "The rank table borders at level %d are not positive and increasing (c < b < a < s < max).",
+	"error.rankTableUnavailable":  "Rank borders for %s are not built in. Specify the table as a JSON file.",
+	"error.judgeTableOpen":        "Could not open the judgement table. (%s)",
+	"error.judgeTableRead":        "Failed to read the judgement table. (%s)",
+	"error.judgeTableLife":        "The judgement table has an invalid initial (%d) or maximum (%d) life.",
+	"error.timelineFormat":        "Unsupported format (%s)",
+	"error.sourceDateEpochUnset":  "Environment variable %s is not set.",
+	"error.sourceDateEpoch":       "Environment variable %s is invalid: %s",
+	"error.invalidTimestamp":      "Invalid timestamp: %s",
+	"error.weightsOpen":           "Could not open the weights file. (%s)",
+	"error.weightsRead":           "Failed to read the weights file. (%s)",
+
+	// AviUtlオブジェクト。インストール時に埋め込む
+	"object.loadFailed":        "(! Load failed !)",
+	"object.pedNotFound":       "ped file not found!",
+	"object.pedInvalid":        "Could not read the ped file!",
+	"object.unsupportedFormat": "Unsupported ped file format (%s)!",
+	"object.noVersion":         "The ped file has no version!",
+	"object.versionMismatch":   "Version mismatch with the ped file!\nThe object file is %s, the ped file is %s.",
+}
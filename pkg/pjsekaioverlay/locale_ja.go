@@ -0,0 +1,312 @@
+package pjsekaioverlay
+
+var catalogJa = Catalog{
+	// 共通
+	"common.success":        "成功",
+	"common.failure":        "失敗：%s",
+	"common.chartId":        "譜面ID: %s",
+	"common.outDir":         "出力先ディレクトリ: %s",
+	"common.assets":         "アセット: %s",
+	"common.unknownCommand": "不明なコマンドです：%s",
+	"common.pressKey":       "\n何かキーを押すと終了します...",
+
+	// 使い方
+	"usage.generate":    "Usage: pjsekai-overlay generate [譜面ID] [オプション]",
+	"usage.fetch":       "Usage: pjsekai-overlay fetch [譜面ID] [オプション]",
+	"usage.score":       "Usage: pjsekai-overlay score [譜面ID] [オプション]",
+	"usage.exo":         "Usage: pjsekai-overlay exo [譜面ID] [オプション]",
+	"usage.stats":       "Usage: pjsekai-overlay stats [譜面ID] [オプション]",
+	"usage.doctor":      "Usage: pjsekai-overlay doctor [オプション]",
+	"usage.relocate":    "Usage: pjsekai-overlay relocate [出力先ディレクトリ] [オプション]",
+	"usage.pedBuild":    "Usage: pjsekai-overlay ped build [譜面ID] [オプション]",
+	"usage.pedInspect":  "Usage: pjsekai-overlay ped inspect [pedファイル] [オプション]",
+	"usage.pedValidate": "Usage: pjsekai-overlay ped validate [pedファイル] [オプション]",
+	"usage.pedDiff":     "Usage: pjsekai-overlay ped diff [pedファイル] [pedファイル] [オプション]",
+	"usage.update":      "Usage: pjsekai-overlay update [オプション]",
+	"usage.regen":       "Usage: pjsekai-overlay regen [出力先ディレクトリ] [オプション]",
+
+	// オプション
+	"flag.outDir":          "出力先ディレクトリを指定します。{title}、{author}、{source}、{difficulty}、{rating}、{date}、{slug}、{chartId}（_chartId_）は譜面の情報に置き換えられます。",
+	"flag.onExists":        "出力先ディレクトリが既にある場合の動作を指定します。（ask：尋ねる / overwrite：上書き / suffix：「-2」などを付ける）",
+	"flag.assets":          "アセットのディレクトリを指定します。省略時は出力先ディレクトリの中のassets、無ければexeと同じ場所のassetsを使います。",
+	"flag.noAviutlInstall": "AviUtlオブジェクトのインストールをスキップします。",
+	"flag.timeline":        "スコアの推移を書き出す形式をカンマ区切りで指定します。（json / csv）",
+	"flag.timelineFps":     "スコアの推移のフレーム番号の計算に使うフレームレートを指定します。",
+	"flag.graph":           "スコアの推移のグラフ（graph.png）を出力します。",
+	"flag.graphDensity":    "グラフにノーツ密度のヒストグラムを追加します。",
+	"flag.scoreBreakdown":  "スコアの内訳を表示します。",
+	"flag.teamPower":       "総合力を指定します。",
+	"flag.weights":         "ノーツの重み設定ファイルを指定します。省略時はexeと同じ場所のweights.jsonを使います。",
+	"flag.judgements":      "判定ファイルを指定します。指定の無いノーツはPerfect扱いになります。",
+	"flag.judgeTable":      "判定毎のスコアの倍率とライフの増減を上書きするJSONファイルを指定します。既定の値（Great 0.8、Good 0.5など）は近似値です。",
+	"flag.skills":          "スキルの発動時間（秒）をカンマ区切りで指定します。",
+	"flag.skillDuration":   "スキルの効果時間（秒）を指定します。",
+	"flag.skillBonus":      "スキルのスコアアップ（%）を指定します。",
+	"flag.apCombo":         "コンボのAP表示を有効にします。",
+	"flag.rankTable":       "ランクのボーダーのテーブルを指定します。（solo / append / JSONファイルのパス）",
+	"flag.fever":           "フィーバーの期間（秒）を「開始-終了」の形式で指定します。",
+	"flag.frameTableFps":   "pedファイルにフレーム毎の表を書き込む時のフレームレートを指定します。0で書き込みません。",
+	"flag.portable":        "アセットを出力先ディレクトリにコピーし、pedファイルに相対パスで書き込みます。",
+	"flag.deterministic":   "同じ譜面とオプションから常に同じpedファイルとexoファイルを生成します。",
+	"flag.timestamp":       "pedファイルに書き込む時刻を指定します。（now / source（SOURCE_DATE_EPOCH） / UNIX時間）",
+	"flag.difficulty":      "exoファイルに表示する難易度を指定します。",
+	"flag.pedDifficulty":   "出力先ディレクトリの{difficulty}に入れる難易度を指定します。",
+	"flag.theme":           "難易度の背景を指定します。（master / append）省略時は両方をexoファイルに入れます。",
+	"flag.resolution":      "exoファイルの解像度を「1920x1080」の形式で指定します。16:9の解像度のみ使えます。",
+	"flag.profile":         "設定ファイルのプロファイルを指定します。",
+	"flag.noConfig":        "設定ファイルを読み込みません。",
+	"flag.yes":             "指定されていないオプションを尋ねず、既定の値を使います。",
+	"flag.noInput":         "入力を一切求めません。必須の値が無い場合はエラーになります。",
+	"flag.offline":         "サーバーへの接続を確認しません。",
+	"flag.events":          "最初と最後に表示するイベントの数を指定します。",
+	"flag.validateRating":  "譜面のレベルを指定すると、ランクとバーの割合がスコアと一致するかも確認します。",
+	"flag.output":          "スコアの推移を書き出すファイルを指定します。（.json / .csv）",
+	"flag.format":          "出力形式を指定します。（table / json / csv）",
+	"flag.json":            "進捗と結果をJSON Lines形式で標準出力に出力します。",
+	"flag.noUpdateCheck":   "新しいバージョンがあるかを確認しません。",
+	"flag.updateCheck":     "新しいバージョンがあるかだけを確認します。",
+	"flag.updateForce":     "最新のバージョンでもダウンロードし直します。",
+	"flag.refresh":         "キャッシュを使わず、譜面の情報、ジャケット、背景を取得し直します。",
+
+	// 入力
+	"prompt.chartId":         "譜面IDをプレフィックス込みで入力して下さい。",
+	"prompt.teamPower":       "総合力を指定してください。",
+	"prompt.apCombo":         "コンボのAP表示を有効にしますか？",
+	"prompt.overwriteOutDir": "%sは既にあります。上書きしますか？（いいえの場合は「-2」などを付けたディレクトリに出力します）",
+
+	// 進捗
+	"progress.fetchChart":     "%s から譜面を取得中... ",
+	"progress.cover":          "ジャケットをダウンロード中... ",
+	"progress.background":     "背景をダウンロード中... ",
+	"progress.parseChart":     "譜面を解析中... ",
+	"progress.judgements":     "判定ファイルを読み込み中... ",
+	"progress.score":          "スコアを計算中... ",
+	"progress.copyAssets":     "アセットをコピー中... ",
+	"progress.ped":            "pedファイルを生成中... ",
+	"progress.exo":            "exoファイルを生成中... ",
+	"progress.timeline":       "スコアの推移を書き出し中... ",
+	"progress.timelineFormat": "スコアの推移（%s）を書き出し中... ",
+	"progress.graph":          "グラフを生成中... ",
+	"progress.relocateExo":    "exoファイルを書き換え中... ",
+	"progress.relocatePed":    "pedファイルを書き換え中... ",
+	"progress.eta":            "残り%s",
+	"progress.checkUpdate":    "最新のバージョンを確認中... ",
+	"progress.downloadUpdate": "%sをダウンロード中... ",
+	"progress.verifyUpdate":   "ダウンロードしたファイルを検証中... ",
+	"progress.applyUpdate":    "ファイルを置き換え中... ",
+	"progress.manifest":       "マニフェストを書き込み中... ",
+
+	// generate
+	"generate.done":            "\n全ての処理が完了しました。READMEの規約を確認した上で、exoファイルをAviUtlにインポートして下さい。",
+	"generate.objectInstalled": "AviUtlオブジェクトのインストールに成功しました。",
+
+	// install
+	"install.aviutlNotFound": "AviUtlが見つかりませんでした。AviUtlを起動してから実行してください。",
+	"install.upToDate":       "AviUtlオブジェクトは既に最新です。",
+
+	// アップデート
+	"update.available": "新しいバージョンがリリースされています：v%s -> v%s",
+	"update.download":  "ダウンロード：%s",
+	"update.command":   "updateコマンドでアップデートできます。",
+	"update.upToDate":  "既に最新のバージョン（v%s）です。",
+	"update.updated":   "v%sにアップデートしました。",
+
+	// regen
+	"regen.version": "v%sで生成されたディレクトリを、v%sで生成し直します。",
+	"regen.done":    "\n出力を生成し直しました。",
+
+	// 警告
+	"warning.unknownArchetypes": "警告：不明なアーキタイプがあります。これらはスコアに含まれません。",
+	"warning.addWeights":        "  重み設定ファイル（weights.json）のエンジン「%s」に重みを追加してください。",
+	"warning.untimedNotes":      "警告：拍を取得できなかったノーツが%d個あります。これらはスコアに含まれません。",
+	"warning.unknownLocale":     "警告：対応していない言語です：%s",
+
+	// スコアの内訳
+	"breakdown.base":     "  基礎スコア：%s\n",
+	"breakdown.level":    "  レベル補正：%s (x%.3f)\n",
+	"breakdown.combo":    "  コンボ補正：%s (最大 x%.2f)\n",
+	"breakdown.skill":    "  スキル補正：%s (最大 x%.2f)\n",
+	"breakdown.total":    "  合計：      %s\n",
+	"breakdown.mismatch": "  最終スコア（%d）と内訳の合計が一致しません。",
+	"score.rank":         "ランク：%s",
+
+	// doctor
+	"doctor.assets":             "アセット",
+	"doctor.weights":            "重み設定",
+	"doctor.object":             "オブジェクト",
+	"doctor.cache":              "キャッシュ",
+	"doctor.missingAssets":      "%sに%sなどがありません。zipを解凍し直してください。",
+	"doctor.aviutlNotRunning":   "起動しているAviUtlが見つかりませんでした。オブジェクトを確認するにはAviUtlを起動してください。",
+	"doctor.objectNotInstalled": "インストールされていません。installコマンドでインストールしてください。",
+	"doctor.objectOutdated":     "バージョンが違います（%s）。installコマンドでインストールし直してください。",
+	"doctor.line":               "%s %s：%s",
+	"doctor.serverError":        "サーバーがエラーを返しました。（%d）",
+
+	// cache
+	"cache.header":  "種類\t名前\tサイズ\t更新日時",
+	"cache.cleared": "キャッシュを削除しました。",
+
+	// ped inspect / validate / diff
+	"inspect.formatVersion":  "形式のバージョン：%d\n",
+	"inspect.version":        "バージョン：      %s\n",
+	"inspect.timestamp":      "生成日時：        %s\n",
+	"inspect.assets":         "アセット：        %s\n",
+	"inspect.ap":             "AP表示：          %t\n",
+	"inspect.scores":         "スコアの数：      %d\n",
+	"inspect.duration":       "長さ：            %.3f秒\n",
+	"inspect.finalScore":     "最終スコア：      %s (%s)\n",
+	"inspect.maxCombo":       "最大コンボ：      %d\n",
+	"inspect.finalLife":      "最終ライフ：      %d\n",
+	"inspect.frameTable":     "フレームの表：    %gfps、%d件\n",
+	"inspect.frameTableNone": "フレームの表：    なし\n",
+	"inspect.events":         "イベント：",
+	"inspect.firstEvents":    "最初のイベント：",
+	"inspect.lastEvents":     "最後のイベント：",
+	"validate.ok":            "問題は見付かりませんでした。",
+	"validate.problems":      "%d個の問題が見付かりました。",
+	"diff.same":              "違いはありません。",
+	"diff.count":             "%d個の違いがあります。",
+
+	// pedファイルの問題
+	"problem.formatVersion":   "形式のバージョンが%dではなく%dです。",
+	"problem.noVersion":       "バージョンが書かれていません。",
+	"problem.version":         "バージョンが%sではなく%sです。",
+	"problem.noAssets":        "アセットのパスが書かれていません。",
+	"problem.noScores":        "スコアがありません。",
+	"problem.barWidth":        "バーの割合（%f）が0〜1の範囲外です。",
+	"problem.life":            "ライフ（%d）が0〜%dの範囲外です。",
+	"problem.firstDelta":      "スコアの増分（%d）がスコア（%d）と一致しません。",
+	"problem.scoreTime":       "時間（%f）が前の時間（%f）より後ではありません。",
+	"problem.delta":           "スコアの増分（%d）が前とのスコアの差（%d）と一致しません。",
+	"problem.scoreDecreased":  "スコア（%d）が前のスコア（%d）より減っています。",
+	"problem.rankDecreased":   "スコアが減っていないのにランクが%sから%sに下がっています。",
+	"problem.barDecreased":    "スコアが増えているのにバーの割合が減っています。",
+	"problem.rank":            "ランクが%sではなく%sです。（スコア：%d）",
+	"problem.rankBarWidth":    "バーの割合が%fではなく%fです。",
+	"problem.eventTime":       "時間（%f）が前の時間（%f）より前です。",
+	"problem.frame":           "フレーム（%d）が前のフレームより後ではありません。",
+	"problem.frameScoreIndex": "sの番号（%d）がsレコードの数（%d）を超えています。",
+
+	// stats
+	"stats.title":           "タイトル\t%s\n",
+	"stats.author":          "譜面作者\t%s\n",
+	"stats.rating":          "レベル\t%d\n",
+	"stats.notes":           "ノーツ数\t%d\n",
+	"stats.comboNotes":      "コンボ対象ノーツ数\t%d\n",
+	"stats.weightedNotes":   "重み付きノーツ数\t%.1f\n",
+	"stats.duration":        "長さ\t%s\n",
+	"stats.maxCombo":        "最大コンボ\t%d\n",
+	"stats.peakNps":         "最大NPS\t%d\n",
+	"stats.finalScore":      "最終スコア\t%d\n",
+	"stats.archetypeHeader": "アーキタイプ\t個数\t重み",
+	"stats.densityHeader":   "秒\tノーツ数",
+	"stats.unknownFormat":   "不明な出力形式です：%s",
+
+	// エラー
+	"error.unknownLocale":         "対応していない言語です：%s",
+	"error.unknownSource":         "譜面のサーバーを判別できませんでした。プレフィックスも込め、正しい譜面IDを入力して下さい。",
+	"error.unsupportedEngine":     "このエンジンはサポートされていません。（バージョン%d）",
+	"error.noChartId":             "譜面IDが指定されていません。%s",
+	"error.noInput":               "入力を求められない状態です。コマンドラインで指定してください。",
+	"error.connect":               "サーバーに接続できませんでした。",
+	"error.connectCause":          "サーバーに接続できませんでした。（%s）",
+	"error.chartNotFound":         "譜面が見つかりませんでした。",
+	"error.chartRead":             "譜面の情報の読み込みに失敗しました。（%s）",
+	"error.parseUrl":              "URLの解析に失敗しました。（%s）",
+	"error.levelDataNotFound":     "譜面データが見つかりませんでした。（%d）",
+	"error.levelDataDownload":     "譜面データのダウンロードに失敗しました。（%s）",
+	"error.levelDataRead":         "譜面データの読み込みに失敗しました。（%s）",
+	"error.coverNotFound":         "ジャケットが見つかりませんでした。（%d）",
+	"error.coverRead":             "ジャケットの読み込みに失敗しました。（%s）",
+	"error.backgroundNotFound":    "背景が見つかりませんでした。（%d）",
+	"error.createFile":            "ファイルの作成に失敗しました。（%s）",
+	"error.writeFile":             "ファイルの書き込みに失敗しました。（%w）",
+	"error.encode":                "エンコードに失敗しました。（%w）",
+	"error.decode":                "デコードに失敗しました。（%w）",
+	"error.encodeImage":           "画像のエンコードに失敗しました。（%s）",
+	"error.cacheDir":              "キャッシュのディレクトリを取得できませんでした。（%s）",
+	"error.cacheWrite":            "キャッシュの書き込みに失敗しました。（%s）",
+	"error.cacheRead":             "キャッシュの読み込みに失敗しました。（%s）",
+	"error.cacheClear":            "キャッシュの削除に失敗しました。（%s）",
+	"error.configValue":           "%sの値が不正です",
+	"error.configProfile":         "プロファイル「%s」の%w",
+	"error.configSources":         "sourcesにはid、host、prefixが必要です",
+	"error.configOpen":            "設定ファイルを開けませんでした。（%s）",
+	"error.configRead":            "設定ファイル（%s）の読み込みに失敗しました。（%s）",
+	"error.configOption":          "設定ファイルの%sが不正です。（%s）",
+	"error.profileNotFound":       "プロファイル「%s」が見つかりません。",
+	"error.invalidSkillTime":      "スキルの発動時間が不正です：%s",
+	"error.invalidTimeWindow":     "期間が不正です：%s",
+	"error.invalidResolution":     "解像度が不正です：%s",
+	"error.resolutionAspect":      "解像度%dx%dは使えません。16:9の解像度（1920x1080など）を指定してください。",
+	"error.invalidTheme":          "テーマが不正です：%s",
+	"error.exoPlaceholder":        "exoファイルの生成に失敗しました（%sが見つかりません）",
+	"error.tooLongText":           "文字列が長すぎます。（%d文字、最大%d文字）",
+	"error.unknownPlaceholder":    "出力先ディレクトリの%sは使えません。",
+	"error.invalidOnExists":       "--on-existsが不正です：%s",
+	"error.manifestOpen":          "マニフェストを開けませんでした。（%s）",
+	"error.manifestRead":          "マニフェストの読み込みに失敗しました。（%s）",
+	"error.manifestFormat":        "マニフェストの形式（%d）に対応していません。",
+	"error.manifestNoChartId":     "マニフェストに譜面IDが書かれていません。",
+	"error.manifestOption":        "マニフェストの%sが不正です。（%s）",
+	"error.manifestHash":          "%sのハッシュの計算に失敗しました。（%s）",
+	"error.invalidVersion":        "バージョンが不正です：%s",
+	"error.offline":               "インターネットに接続されていません。",
+	"error.downloadStatus":        "ダウンロードに失敗しました。（%d）",
+	"error.updateAssetNotFound":   "リリースに%sがありません。",
+	"error.updateChecksumRead":    "ハッシュのファイルの読み込みに失敗しました。（%s）",
+	"error.updateChecksumMissing": "ハッシュのファイルに%sがありません。",
+	"error.updateChecksum":        "ダウンロードしたファイルのハッシュが一致しません。",
+	"error.updateSize":            "ダウンロードしたファイルのサイズ（%d）が%dではありません。",
+	"error.updateZip":             "ダウンロードしたzipが壊れています。（%s）",
+	"error.updateZipPath":         "zipに不正なパスがあります：%s",
+	"error.updateMissingFile":     "ダウンロードしたzipに%sがありません。",
+	"error.updateReplace":         "ファイルの置き換えに失敗しました。（%s）",
+	"error.unknownJudgement":      "不明な判定です：%s",
+	"error.judgementLine":         "判定ファイルの%d行目が不正です。",
+	"error.judgementLineCause":    "判定ファイルの%d行目が不正です。（%s）",
+	"error.judgementNoteIndex":    "判定ファイルの%d行目のノーツ番号が不正です。",
+	"error.judgementRead":         "判定ファイルの読み込みに失敗しました。（%s）",
+	"error.judgementOpen":         "判定ファイルを開けませんでした。（%s）",
+	"error.pedLine":               "pedファイルの%d行目が不正です。",
+	"error.pedRead":               "pedファイルの読み込みに失敗しました。（%s）",
+	"error.pedScore":              "pedファイルの%d行目のスコアが不正です。",
+	"error.pedScoreCause":         "pedファイルの%d行目のスコアが不正です。（%s）",
+	"error.pedRank":               "pedファイルの%d行目のランクが不正です。（%s）",
+	"error.pedEvent":              "pedファイルの%d行目のイベントが不正です。",
+	"error.pedEventCause":         "pedファイルの%d行目のイベントが不正です。（%s）",
+	"error.pedFrameTable":         "pedファイルの%d行目のフレームの表が不正です。",
+	"error.pedFormatVersion":      "pedファイルの形式のバージョンが不正です。（%s）",
+	"error.pedFormatNotFirst":     "pedファイルの%d行目に形式のバージョンがあります。形式のバージョンは最初の行に書く必要があります。",
+	"error.pedUnsupportedFormat":  "pedファイルの形式（%d）に対応していません。",
+	"error.pedFrameRate":          "pedファイルの%d行目のフレームレートが不正です。",
+	"error.pedNoFrameRate":        "pedファイルの%d行目より前にフレームレートがありません。",
+	"error.pedOpen":               "pedファイルを開けませんでした。（%s）",
+	"error.pedNoAssets":           "pedファイルにアセットのパスが書かれていません。",
+	"error.copyAssets":            "アセットのコピーに失敗しました。（%w）",
+	"error.exoOpen":               "exoファイルを開けませんでした。（%s）",
+	"error.exoNoPed":              "exoファイルにpedファイルのパスが見つかりません。",
+	"error.rankTableOpen":         "ランクのテーブルを開けませんでした。（%s）",
+	"error.rankTableRead":         "ランクのテーブルの読み込みに失敗しました。（%s）",
+	"error.rankTableBarWidth":     "ランクのテーブルのバーの幅が不正です。",
+	"error.rankTableRating":       "ランクのテーブルの最小のレベル（%d）が最大のレベル（%d）より大きいです。",
+	"error.rankTableBorders":      "ランクのテーブルのボーダーが、レベル%dで0より大きく c < b < a < s < max の順になっていません。",
+	"error.rankTableUnavailable":  "%sのランクのボーダーは組み込まれていません。JSONファイルでテーブルを指定してください。",
+	"error.judgeTableOpen":        "判定のテーブルを開けませんでした。（%s）",
+	"error.judgeTableRead":        "判定のテーブルの読み込みに失敗しました。（%s）",
+	"error.judgeTableLife":        "判定のテーブルのライフの初期値（%d）か最大値（%d）が不正です。",
+	"error.timelineFormat":        "対応していない形式です（%s）",
+	"error.sourceDateEpochUnset":  "環境変数%sが設定されていません。",
+	"error.sourceDateEpoch":       "環境変数%sが不正です：%s",
+	"error.invalidTimestamp":      "時刻が不正です：%s",
+	"error.weightsOpen":           "重み設定ファイルを開けませんでした。（%s）",
+	"error.weightsRead":           "重み設定ファイルの読み込みに失敗しました。（%s）",
+
+	// AviUtlオブジェクト。インストール時に埋め込む
+	"object.loadFailed":        "(! 読み込み失敗 !)",
+	"object.pedNotFound":       "pedファイルが見付かりません！",
+	"object.pedInvalid":        "pedファイルを読み取れません！",
+	"object.unsupportedFormat": "pedファイルの形式（%s）に対応していません！",
+	"object.noVersion":         "pedファイルのバージョンが設定されていません！",
+	"object.versionMismatch":   "pedファイルとのバージョンが違います！\nオブジェクトファイルは%s、pedファイルは%sです。",
+}
@@ -0,0 +1,109 @@
+package pjsekaioverlay
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/sevenc-nanashi/pjsekai-overlay/pkg/sonolus"
+)
+
+// 出力先ディレクトリに書き込む、生成に使った情報のファイル。
+const ManifestName = "pjsekai-overlay.manifest.json"
+
+const ManifestFormatVersion = 1
+
+// ハッシュを記録する出力先ディレクトリのファイル
+var MANIFEST_FILES = []string{"cover.png", "background.png", "data.ped", "main.exo"}
+
+type Manifest struct {
+	FormatVersion int    `json:"formatVersion"`
+	ChartId       string `json:"chartId"`
+	// Source.Id
+	Source string `json:"source"`
+	// 生成したpjsekai-overlayのバージョン
+	Version string `json:"version"`
+	// オプション名（先頭の--を除いたもの）と値。設定ファイルのoptionsと同じ形式
+	Options map[string]string `json:"options"`
+	// サーバーの譜面データ、ジャケット、背景のハッシュ
+	RemoteHashes map[string]string `json:"remoteHashes"`
+	// 出力先ディレクトリのファイルのSHA-256
+	FileHashes map[string]string `json:"fileHashes"`
+}
+
+func NewManifest(chartId string, source Source, chart sonolus.LevelInfo, options map[string]string) Manifest {
+	return Manifest{
+		FormatVersion: ManifestFormatVersion,
+		ChartId:       chartId,
+		Source:        source.Id,
+		Version:       Version,
+		Options:       options,
+		RemoteHashes: map[string]string{
+			"levelData":  chart.Data.Hash,
+			"cover":      chart.Cover.Hash,
+			"background": chart.UseBackground.Item.Image.Hash,
+		},
+		FileHashes: map[string]string{},
+	}
+}
+
+func hashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// MANIFEST_FILESのうち、dirにあるもののハッシュを記録する。
+func (manifest *Manifest) HashFiles(dir string) error {
+	manifest.FileHashes = map[string]string{}
+	for _, name := range MANIFEST_FILES {
+		hash, err := hashFile(filepath.Join(dir, name))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return NewError(ErrorFilesystem, err, "error.manifestHash", name, err)
+		}
+		manifest.FileHashes[name] = hash
+	}
+	return nil
+}
+
+func WriteManifest(dir string, manifest Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return NewError(ErrorEncoding, err, "error.encode", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ManifestName), append(data, '\n'), 0644); err != nil {
+		return NewError(ErrorFilesystem, err, "error.writeFile", err)
+	}
+	return nil
+}
+
+func ReadManifest(dir string) (Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestName))
+	if err != nil {
+		return Manifest{}, NewError(ErrorFilesystem, err, "error.manifestOpen", err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return Manifest{}, NewError(ErrorDecode, err, "error.manifestRead", err)
+	}
+	if manifest.FormatVersion > ManifestFormatVersion {
+		return Manifest{}, NewError(ErrorDecode, nil, "error.manifestFormat", manifest.FormatVersion)
+	}
+	if manifest.ChartId == "" {
+		return Manifest{}, NewError(ErrorDecode, nil, "error.manifestNoChartId")
+	}
+	return manifest, nil
+}
@@ -0,0 +1,123 @@
+package pjsekaioverlay
+
+import (
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// 出力先ディレクトリのテンプレートに埋め込む値。
+type OutDirValues struct {
+	ChartId string
+	Title   string
+	Author  string
+	// Source.Id
+	Source string
+	// 空の場合はMASTER
+	Difficulty string
+	Rating     int
+	Date       time.Time
+}
+
+// 埋め込む値の最大の文字数
+const maxOutDirValueLength = 100
+
+var outDirPlaceholderPattern = regexp.MustCompile(`\{[^{}]*\}`)
+
+// Windowsで使えないファイル名
+var windowsReservedNames = regexp.MustCompile(`(?i)^(con|prn|aux|nul|com[1-9]|lpt[1-9])(\..*)?$`)
+
+// Windowsのファイル名に使えない文字を「_」に置き換える。
+func SanitizeFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || strings.ContainsRune(`<>:"/\|?*`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if runes := []rune(name); len(runes) > maxOutDirValueLength {
+		name = string(runes[:maxOutDirValueLength])
+	}
+	// 末尾の空白とピリオドはWindowsで取り除かれてしまう
+	name = strings.TrimRight(name, " .")
+	if windowsReservedNames.MatchString(name) {
+		name = "_" + name
+	}
+	if name == "" {
+		return "_"
+	}
+	return name
+}
+
+// 文字と数字以外を「-」にまとめ、小文字にする。
+func Slugify(text string) string {
+	var builder strings.Builder
+	pendingHyphen := false
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			if pendingHyphen && builder.Len() > 0 {
+				builder.WriteRune('-')
+			}
+			pendingHyphen = false
+			builder.WriteRune(r)
+		} else {
+			pendingHyphen = true
+		}
+	}
+	return builder.String()
+}
+
+// 「./dist/{source}/{title}」のようなテンプレートに値を埋め込む。
+// 埋め込んだ値はファイル名に使えるように置き換えられる。以前の「_chartId_」も使える。
+func FormatOutDir(template string, values OutDirValues) (string, error) {
+	difficulty := values.Difficulty
+	if difficulty == "" {
+		difficulty = "MASTER"
+	}
+	slug := Slugify(values.Title)
+	if slug == "" {
+		slug = Slugify(values.ChartId)
+	}
+	replacements := map[string]string{
+		"chartId":    values.ChartId,
+		"title":      values.Title,
+		"author":     values.Author,
+		"source":     values.Source,
+		"difficulty": difficulty,
+		"rating":     strconv.Itoa(values.Rating),
+		"date":       values.Date.Format("2006-01-02"),
+		"slug":       slug,
+	}
+
+	var unknownPlaceholder string
+	formatted := outDirPlaceholderPattern.ReplaceAllStringFunc(template, func(placeholder string) string {
+		value, ok := replacements[placeholder[1:len(placeholder)-1]]
+		if !ok {
+			if unknownPlaceholder == "" {
+				unknownPlaceholder = placeholder
+			}
+			return placeholder
+		}
+		return SanitizeFileName(value)
+	})
+	if unknownPlaceholder != "" {
+		return "", Errorf("error.unknownPlaceholder", unknownPlaceholder)
+	}
+	return strings.ReplaceAll(formatted, "_chartId_", SanitizeFileName(values.ChartId)), nil
+}
+
+// pathが既にある場合は「-2」「-3」などを付けた、まだ無いパスを返す。
+func UniqueDir(path string) string {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return path
+	}
+	for i := 2; ; i++ {
+		candidate := path + "-" + strconv.Itoa(i)
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
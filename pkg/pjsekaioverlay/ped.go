@@ -2,10 +2,9 @@ package pjsekaioverlay
 
 import (
 	"fmt"
-	"io"
+	"math"
 	"os"
 	"sort"
-	"strconv"
 	"time"
 
 	"github.com/sevenc-nanashi/pjsekai-overlay/pkg/sonolus"
@@ -13,12 +12,69 @@ import (
 
 type PedFrame struct {
 	Time  float64
-	Score float64
+	Score int
+	Combo int
+	Life  int
 }
 
-type BpmChange struct {
-	Beat float64
-	Bpm  float64
+type ScoreOptions struct {
+	Power      int
+	Judgements map[int]Judgement
+	Life       LifeTable
+	// nilの場合はJUDGE_WEIGHT_MAPを使う
+	JudgeWeights map[Judgement]float64
+	// nilの場合はWEIGHT_MAPを使う
+	Weights map[string]float64
+	Skills  []SkillWindow
+}
+
+// スコアの内訳。各補正は前の補正までを掛けたスコアとの差分で、
+// ノーツ毎に切り捨てた値を合計したもの。
+type ScoreBreakdown struct {
+	Base  int `json:"base"`
+	Level int `json:"level"`
+	Combo int `json:"combo"`
+	Skill int `json:"skill"`
+	Total int `json:"total"`
+
+	LevelFax float64 `json:"levelFax"`
+	// スキル補正の最大値
+	SkillFax float64 `json:"skillFax"`
+	// コンボ補正の最大値
+	MaxComboFax float64 `json:"maxComboFax"`
+}
+
+// 拍が取得できず、スコアの計算から除外されたノーツ
+type UntimedNote struct {
+	// LevelData.Entities内の位置
+	Index     int
+	Archetype string
+}
+
+// 時間順に並べたノーツ1つ毎の計算結果
+type ScoredNote struct {
+	Time      float64
+	Archetype string
+	Weight    float64
+	Judgement Judgement
+	// このノーツで加算されたスコア
+	Score int
+	// このノーツを処理した後のコンボとライフ
+	Combo int
+	Life  int
+}
+
+type ScoreResult struct {
+	Frames       []PedFrame
+	Notes        []ScoredNote
+	Breakdown    ScoreBreakdown
+	UntimedNotes []UntimedNote
+}
+
+type timedNote struct {
+	entity sonolus.LevelDataEntity
+	weight float64
+	time   float64
 }
 
 var WEIGHT_MAP = map[string]float64{
@@ -96,169 +152,213 @@ func getValueFromData(data []sonolus.LevelDataEntityValue, name string) (float64
 	return 0, fmt.Errorf("value not found: %s", name)
 }
 
-func getTimeFromBpmChanges(bpmChanges []BpmChange, beat float64) float64 {
-	ret := 0.0
-	for i, bpmChange := range bpmChanges {
-		if i == len(bpmChanges)-1 {
-			ret += (beat - bpmChange.Beat) * (60 / bpmChange.Bpm)
-			break
-		}
-		nextBpmChange := bpmChanges[i+1]
-		if beat >= bpmChange.Beat && beat < nextBpmChange.Beat {
-			ret += (beat - bpmChange.Beat) * (60 / bpmChange.Bpm)
-			break
-		} else if beat >= nextBpmChange.Beat {
-			ret += (nextBpmChange.Beat - bpmChange.Beat) * (60 / bpmChange.Bpm)
-		} else {
-			break
-		}
-	}
-	return ret
-}
+// pedファイルの時間の最小の間隔（小数点以下6桁）
+const pedTimeStep = 0.000001
 
-func CalculateScore(levelInfo sonolus.LevelInfo, levelData sonolus.LevelData, power int) []PedFrame {
+func CalculateScore(levelInfo sonolus.LevelInfo, levelData sonolus.LevelData, options ScoreOptions) ScoreResult {
+	power := options.Power
 	rating := levelInfo.Rating
+	weights := options.Weights
+	if weights == nil {
+		weights = WEIGHT_MAP
+	}
+	judgeWeights := options.JudgeWeights
+	if judgeWeights == nil {
+		judgeWeights = JUDGE_WEIGHT_MAP
+	}
+	tempoMap := NewTempoMapFromLevelData(levelData)
+
 	var weightedNotesCount float64 = 0
-	for _, entity := range levelData.Entities {
-		weight := WEIGHT_MAP[entity.Archetype]
+	notes := []timedNote{}
+	untimedNotes := []UntimedNote{}
+	for i, entity := range levelData.Entities {
+		weight := weights[entity.Archetype]
 		if weight == 0 {
 			continue
 		}
+		beat, err := getValueFromData(entity.Data, "#BEAT")
+		if err != nil {
+			untimedNotes = append(untimedNotes, UntimedNote{Index: i, Archetype: entity.Archetype})
+			continue
+		}
 		weightedNotesCount += weight
+		notes = append(notes, timedNote{
+			entity: entity,
+			weight: weight,
+			time:   tempoMap.Time(beat),
+		})
 	}
+	sort.SliceStable(notes, func(i, j int) bool {
+		return notes[i].time < notes[j].time
+	})
 
-	frames := make([]PedFrame, 0, int(weightedNotesCount)+1)
-	frames = append(frames, PedFrame{Time: 0, Score: 0})
-	bpmChanges := ([]BpmChange{})
+	// 最初のフレームは、最初のノーツのフレームと同じ時間にならないように少し前にする
+	startTime := 0.0
+	if len(notes) > 0 && notes[0].time+levelData.BgmOffset < 0 {
+		startTime = notes[0].time + levelData.BgmOffset - pedTimeStep
+	}
+	frames := make([]PedFrame, 0, len(notes)+1)
+	frames = append(frames, PedFrame{Time: startTime, Score: 0, Life: options.Life.Initial})
+	scoredNotes := make([]ScoredNote, 0, len(notes))
 	levelFax := float64(rating-5)*0.005 + 1
-	comboFax := 1.0
+	breakdown := ScoreBreakdown{
+		LevelFax:    levelFax,
+		SkillFax:    1,
+		MaxComboFax: 1,
+	}
 
-	score := 0.0
+	score := 0
+	combo := 0
+	life := options.Life.Initial
 	entityCounter := 0
-	noteEntities := ([]sonolus.LevelDataEntity{})
-
-	for _, entity := range levelData.Entities {
-		weight := WEIGHT_MAP[entity.Archetype]
-		if weight > 0.0 && len(entity.Data) > 0 {
-			noteEntities = append(noteEntities, entity)
-		} else if entity.Archetype == "#BPM_CHANGE" {
-			beat, err := getValueFromData(entity.Data, "#BEAT")
-			if err != nil {
-				continue
-			}
-			bpm, err := getValueFromData(entity.Data, "#BPM")
-			if err != nil {
-				continue
-			}
-			bpmChanges = append(bpmChanges, BpmChange{
-				Beat: beat,
-				Bpm:  bpm,
-			})
-		}
-	}
-	sort.SliceStable(noteEntities, func(i, j int) bool {
-		return noteEntities[i].Data[0].Value < noteEntities[j].Data[0].Value
-	})
-	sort.SliceStable(bpmChanges, func(i, j int) bool {
-		return bpmChanges[i].Beat < bpmChanges[j].Beat
-	})
-	for _, entity := range noteEntities {
-		weight := WEIGHT_MAP[entity.Archetype]
+
+	for _, note := range notes {
+		entity := note.entity
+		weight := note.weight
 		entityCounter += 1
-		if entityCounter%100 == 1 && entityCounter > 1 {
-			comboFax += 0.01
+
+		judgement, ok := options.Judgements[entityCounter]
+		if !ok {
+			judgement = JudgementPerfect
 		}
-		if comboFax > 1.1 {
-			comboFax = 1.1
+		combo = nextCombo(combo, entity.Archetype, weight, judgement)
+		comboFax := getComboFax(combo)
+		if comboFax > breakdown.MaxComboFax {
+			breakdown.MaxComboFax = comboFax
 		}
-
-		score += ((float64(power) / weightedNotesCount) * // Team power / weighted notes count
-				4 * // Constant
-				weight * // Note weight
-				1 * // Judge weight (Always 1)
-				levelFax * // Level fax
-				comboFax * // Combo fax
-				1) // Skill fax (Always 1)
-		beat, err := getValueFromData(entity.Data, "#BEAT")
-		if err != nil {
-			continue
+		// スキルは重ならず、一番大きいものが適用される
+		skillFax := 1.0
+		for _, skill := range options.Skills {
+			if skill.Contains(note.time+levelData.BgmOffset) && 1+skill.Bonus > skillFax {
+				skillFax = 1 + skill.Bonus
+			}
 		}
-		frames = append(frames, PedFrame{
-			Time:  getTimeFromBpmChanges(bpmChanges, beat) + levelData.BgmOffset,
-			Score: score,
+		if skillFax > breakdown.SkillFax {
+			breakdown.SkillFax = skillFax
+		}
+
+		baseScore := ((float64(power) / weightedNotesCount) * // Team power / weighted notes count
+			4 * // Constant
+			weight * // Note weight
+			judgeWeights[judgement]) // Judge weight
+		levelScore := int(math.Floor(baseScore * levelFax))
+		comboScore := int(math.Floor(baseScore * levelFax * comboFax))
+		noteScore := int(math.Floor(baseScore * levelFax * comboFax * skillFax))
+
+		breakdown.Base += int(math.Floor(baseScore))
+		breakdown.Level += levelScore - int(math.Floor(baseScore))
+		breakdown.Combo += comboScore - levelScore
+		breakdown.Skill += noteScore - comboScore
+		breakdown.Total += noteScore
+
+		score += noteScore
+		life = options.Life.Apply(life, options.Life.Change(entity.Archetype, weight, judgement))
+		scoredNotes = append(scoredNotes, ScoredNote{
+			Time:      note.time + levelData.BgmOffset,
+			Archetype: entity.Archetype,
+			Weight:    weight,
+			Judgement: judgement,
+			Score:     noteScore,
+			Combo:     combo,
+			Life:      life,
 		})
+		frame := PedFrame{
+			Time:  note.time + levelData.BgmOffset,
+			Score: score,
+			Combo: combo,
+			Life:  life,
+		}
+		// 同時押しは1つのフレームにまとめる
+		if len(frames) > 1 && frames[len(frames)-1].Time == frame.Time {
+			frames[len(frames)-1] = frame
+		} else {
+			frames = append(frames, frame)
+		}
 	}
 
-	return frames
-}
-
-func WritePedFile(frames []PedFrame, assets string, ap bool, path string, levelInfo sonolus.LevelInfo) error {
-	file, err := os.Create(path)
-	if err != nil {
-		return fmt.Errorf("ファイルの作成に失敗しました（%s）", err)
+	return ScoreResult{
+		Frames:       frames,
+		Notes:        scoredNotes,
+		Breakdown:    breakdown,
+		UntimedNotes: untimedNotes,
 	}
-	defer file.Close()
+}
 
-	writer := io.Writer(file)
+type PedOptions struct {
+	Assets    string
+	Ap        bool
+	Life      LifeTable
+	RankTable RankTable
+	LevelInfo sonolus.LevelInfo
+	Skills    []SkillWindow
+	Fever     *TimeWindow
+	// 0より大きい場合、このフレームレートのフレーム毎の表を書き込む
+	FrameTableFps float64
+	// u|に書き込む時刻。nilの場合は現在の時刻
+	Timestamp *int64
+}
 
-	writer.Write([]byte(fmt.Sprintf("p|%s\n", assets)))
-	writer.Write([]byte(fmt.Sprintf("a|%s\n", strconv.FormatBool(ap))))
-	writer.Write([]byte(fmt.Sprintf("v|%s\n", Version)))
-	writer.Write([]byte(fmt.Sprintf("u|%d\n", time.Now().Unix())))
+func BuildPedFile(scoreData ScoreResult, options PedOptions) PedFile {
+	frames := scoreData.Frames
+	timestamp := time.Now().Unix()
+	if options.Timestamp != nil {
+		timestamp = *options.Timestamp
+	}
+	pedFile := PedFile{
+		FormatVersion: PedFormatVersion,
+		Assets:        options.Assets,
+		Ap:            options.Ap,
+		Version:       Version,
+		Timestamp:     timestamp,
+		LifeMax:       options.Life.Max,
+		Scores:        make([]PedScore, 0, len(frames)),
+		Events:        BuildPedEvents(scoreData, options),
+	}
 
-	lastScore := 0.0
-	rating := levelInfo.Rating
+	lastScore := 0
+	rating := options.LevelInfo.Rating
 	for i, frame := range frames {
-		score := frame.Score
-		frameScore := score - lastScore
+		frameScore := frame.Score - lastScore
 		lastScore = frame.Score
 
-		// 161, 215, 267, 320, 357
+		rank, barWidth := options.RankTable.Rank(frame.Score, rating)
 
-		rank := "n"
-		scoreX := 0.0
-
-		// rank
-		if rating < 5 {
-			rating = 5
-		} else if rating > 40 {
-			rating = 40
-		}
-
-		rankBorder := float64(1200000 + (rating-5)*4100)
-		rankS := float64(1040000 + (rating-5)*5200)
-		rankA := float64(840000 + (rating-5)*4200)
-		rankB := float64(400000 + (rating-5)*2000)
-		rankC := float64(20000 + (rating-5)*100)
-
-		// bar
-		if score >= rankBorder {
-			rank = "s"
-			scoreX = 357
-		} else if score >= rankS {
-			rank = "s"
-			scoreX = (float64((score-rankS))/float64((rankBorder-rankS)))*37 + 320
-		} else if score >= rankA {
-			rank = "a"
-			scoreX = (float64((score-rankA))/float64((rankS-rankA)))*53 + 267
-		} else if score >= rankB {
-			rank = "b"
-			scoreX = (float64((score-rankB))/float64((rankA-rankB)))*53 + 215
-		} else if score >= rankC {
-			rank = "c"
-			scoreX = (float64((score-rankC))/float64((rankB-rankC)))*54 + 161
-		} else {
-			rank = "d"
-			scoreX = (float64(score) / float64(rankC)) * 160
-		}
-		
 		time := frame.Time
 		if time == 0 && i > 0 {
-			time = frames[i-1].Time + 0.000001
+			time = frames[i-1].Time + pedTimeStep
 		}
-		
-		writer.Write([]byte(fmt.Sprintf("s|%f:%f:%f:%f:%s:%d\n", time, score, frameScore, scoreX/357, rank, i)))
+
+		pedFile.Scores = append(pedFile.Scores, PedScore{
+			Time:     time,
+			Score:    frame.Score,
+			Delta:    frameScore,
+			BarWidth: barWidth,
+			Rank:     rank,
+			Combo:    frame.Combo,
+			Life:     frame.Life,
+		})
+	}
+	if options.FrameTableFps > 0 {
+		pedFile.FrameTable = BuildPedFrameTable(pedFile.Scores, pedFile.Events, options.FrameTableFps)
+	}
+
+	return pedFile
+}
+
+func WritePedFile(scoreData ScoreResult, path string, options PedOptions) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return NewError(ErrorFilesystem, err, "error.createFile", err)
 	}
 
+	err = WritePed(file, BuildPedFile(scoreData, options))
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return NewError(ErrorFilesystem, err, "error.writeFile", err)
+	}
 	return nil
 }
@@ -0,0 +1,46 @@
+package pjsekaioverlay
+
+import (
+	"testing"
+
+	"github.com/sevenc-nanashi/pjsekai-overlay/pkg/sonolus"
+)
+
+func beatEntity(archetype string, beat float64) sonolus.LevelDataEntity {
+	return sonolus.LevelDataEntity{
+		Archetype: archetype,
+		Data:      []sonolus.LevelDataEntityValue{{Name: "#BEAT", Value: beat}},
+	}
+}
+
+func TestNegativeFirstNoteIsValid(t *testing.T) {
+	levelData := sonolus.LevelData{
+		// 最初のノーツは-0.5秒
+		BgmOffset: -1,
+		Entities: []sonolus.LevelDataEntity{
+			beatEntity("NormalTapNote", 0.5),
+			beatEntity("CriticalTapNote", 0.5),
+			beatEntity("NormalTapNote", 2),
+		},
+	}
+	scoreData := CalculateScore(sonolus.LevelInfo{Rating: 30}, levelData, ScoreOptions{
+		Power: 250000,
+		Life:  DEFAULT_LIFE_TABLE,
+	})
+	if len(scoreData.Frames) != 3 {
+		t.Fatalf("len(Frames) = %d, want 3", len(scoreData.Frames))
+	}
+	if scoreData.Frames[0].Time >= scoreData.Frames[1].Time {
+		t.Errorf("initial frame at %f is not before the first note at %f", scoreData.Frames[0].Time, scoreData.Frames[1].Time)
+	}
+
+	pedFile := BuildPedFile(scoreData, PedOptions{
+		Assets:    "assets",
+		Life:      DEFAULT_LIFE_TABLE,
+		RankTable: SOLO_RANK_TABLE,
+		LevelInfo: sonolus.LevelInfo{Rating: 30},
+	})
+	for _, problem := range ValidatePed(pedFile) {
+		t.Errorf("%s#%d: %s", problem.Record, problem.Index, problem.Message)
+	}
+}
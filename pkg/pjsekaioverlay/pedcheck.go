@@ -0,0 +1,238 @@
+package pjsekaioverlay
+
+import (
+	"fmt"
+	"math"
+)
+
+type PedSummary struct {
+	FormatVersion int
+	Version       string
+	Timestamp     int64
+	Assets        string
+	Ap            bool
+
+	Scores     int
+	Events     map[string]int
+	FrameTable *PedFrameTable
+
+	Duration   float64
+	FinalScore int
+	FinalRank  string
+	MaxCombo   int
+	FinalLife  int
+
+	FirstEvents []PedEvent
+	LastEvents  []PedEvent
+}
+
+type PedProblem struct {
+	// sレコードやeレコードの番号（1から）。ファイル全体の問題は0
+	Index   int
+	Record  string
+	Message string
+}
+
+type PedDifference struct {
+	Record string
+	Index  int
+	Left   string
+	Right  string
+}
+
+var RANK_ORDER = map[string]int{
+	"d": 0,
+	"c": 1,
+	"b": 2,
+	"a": 3,
+	"s": 4,
+}
+
+// eventCountは0からイベントの数までに収める。
+func SummarizePed(pedFile PedFile, eventCount int) PedSummary {
+	summary := PedSummary{
+		FormatVersion: pedFile.FormatVersion,
+		Version:       pedFile.Version,
+		Timestamp:     pedFile.Timestamp,
+		Assets:        pedFile.Assets,
+		Ap:            pedFile.Ap,
+		Scores:        len(pedFile.Scores),
+		Events:        map[string]int{},
+		FrameTable:    pedFile.FrameTable,
+	}
+	for _, pedScore := range pedFile.Scores {
+		if pedScore.Combo > summary.MaxCombo {
+			summary.MaxCombo = pedScore.Combo
+		}
+	}
+	if len(pedFile.Scores) > 0 {
+		last := pedFile.Scores[len(pedFile.Scores)-1]
+		summary.Duration = last.Time - pedFile.Scores[0].Time
+		summary.FinalScore = last.Score
+		summary.FinalRank = last.Rank
+		summary.FinalLife = last.Life
+	}
+	for _, pedEvent := range pedFile.Events {
+		summary.Events[pedEvent.Type]++
+	}
+	if eventCount < 0 {
+		eventCount = 0
+	} else if eventCount > len(pedFile.Events) {
+		eventCount = len(pedFile.Events)
+	}
+	summary.FirstEvents = pedFile.Events[:eventCount]
+	summary.LastEvents = pedFile.Events[len(pedFile.Events)-eventCount:]
+
+	return summary
+}
+
+func ValidatePed(pedFile PedFile) []PedProblem {
+	problems := []PedProblem{}
+	addProblem := func(record string, index int, key string, args ...any) {
+		problems = append(problems, PedProblem{Index: index, Record: record, Message: T(key, args...)})
+	}
+
+	if pedFile.FormatVersion != PedFormatVersion {
+		addProblem("f", 0, "problem.formatVersion", PedFormatVersion, pedFile.FormatVersion)
+	}
+	if pedFile.Version == "" {
+		addProblem("v", 0, "problem.noVersion")
+	} else if pedFile.Version != Version {
+		addProblem("v", 0, "problem.version", Version, pedFile.Version)
+	}
+	if pedFile.Assets == "" {
+		addProblem("p", 0, "problem.noAssets")
+	}
+	if len(pedFile.Scores) == 0 {
+		addProblem("s", 0, "problem.noScores")
+	}
+
+	for i, pedScore := range pedFile.Scores {
+		if pedScore.BarWidth < 0 || pedScore.BarWidth > 1 || math.IsNaN(pedScore.BarWidth) {
+			addProblem("s", i+1, "problem.barWidth", pedScore.BarWidth)
+		}
+		if pedScore.Life < 0 || (pedFile.LifeMax > 0 && pedScore.Life > pedFile.LifeMax) {
+			addProblem("s", i+1, "problem.life", pedScore.Life, pedFile.LifeMax)
+		}
+		if i == 0 {
+			if pedScore.Delta != pedScore.Score {
+				addProblem("s", i+1, "problem.firstDelta", pedScore.Delta, pedScore.Score)
+			}
+			continue
+		}
+		previous := pedFile.Scores[i-1]
+		if pedScore.Time <= previous.Time {
+			addProblem("s", i+1, "problem.scoreTime", pedScore.Time, previous.Time)
+		}
+		if pedScore.Score-previous.Score != pedScore.Delta {
+			addProblem("s", i+1, "problem.delta", pedScore.Delta, pedScore.Score-previous.Score)
+		}
+		if pedScore.Score < previous.Score {
+			addProblem("s", i+1, "problem.scoreDecreased", pedScore.Score, previous.Score)
+		} else if RANK_ORDER[pedScore.Rank] < RANK_ORDER[previous.Rank] {
+			addProblem("s", i+1, "problem.rankDecreased", previous.Rank, pedScore.Rank)
+		}
+		if pedScore.Score > previous.Score && pedScore.BarWidth < previous.BarWidth {
+			addProblem("s", i+1, "problem.barDecreased")
+		}
+	}
+
+	for i, pedEvent := range pedFile.Events {
+		if i > 0 && pedEvent.Time < pedFile.Events[i-1].Time {
+			addProblem("e", i+1, "problem.eventTime", pedEvent.Time, pedFile.Events[i-1].Time)
+		}
+	}
+
+	if pedFile.FrameTable != nil {
+		for i, run := range pedFile.FrameTable.Runs {
+			if i > 0 && run.Frame <= pedFile.FrameTable.Runs[i-1].Frame {
+				addProblem("i", i+1, "problem.frame", run.Frame)
+			}
+			if run.Score > len(pedFile.Scores) {
+				addProblem("i", i+1, "problem.frameScoreIndex", run.Score, len(pedFile.Scores))
+			}
+		}
+	}
+
+	return problems
+}
+
+// pedファイルのバーの割合は小数点以下6桁で書かれる
+const pedBarWidthTolerance = 0.000001
+
+// 各sレコードのランクとバーの割合が、tableとratingから計算したものと一致するかを確認する。
+func ValidatePedRanks(pedFile PedFile, table RankTable, rating int) []PedProblem {
+	problems := []PedProblem{}
+	for i, pedScore := range pedFile.Scores {
+		rank, barWidth := table.Rank(pedScore.Score, rating)
+		if pedScore.Rank != rank {
+			problems = append(problems, PedProblem{Index: i + 1, Record: "s", Message: T("problem.rank", rank, pedScore.Rank, pedScore.Score)})
+		}
+		if math.Abs(pedScore.BarWidth-barWidth) > pedBarWidthTolerance {
+			problems = append(problems, PedProblem{Index: i + 1, Record: "s", Message: T("problem.rankBarWidth", barWidth, pedScore.BarWidth)})
+		}
+	}
+	return problems
+}
+
+func DiffPed(left PedFile, right PedFile) []PedDifference {
+	differences := []PedDifference{}
+	compare := func(record string, index int, leftValue string, rightValue string) {
+		if leftValue != rightValue {
+			differences = append(differences, PedDifference{Record: record, Index: index, Left: leftValue, Right: rightValue})
+		}
+	}
+
+	compare("f", 0, fmt.Sprint(left.FormatVersion), fmt.Sprint(right.FormatVersion))
+	compare("p", 0, left.Assets, right.Assets)
+	compare("a", 0, fmt.Sprint(left.Ap), fmt.Sprint(right.Ap))
+	compare("v", 0, left.Version, right.Version)
+	compare("u", 0, fmt.Sprint(left.Timestamp), fmt.Sprint(right.Timestamp))
+	compare("l", 0, fmt.Sprint(left.LifeMax), fmt.Sprint(right.LifeMax))
+
+	compareLists := func(record string, leftValues []string, rightValues []string) {
+		for i := 0; i < len(leftValues) || i < len(rightValues); i++ {
+			leftValue, rightValue := "", ""
+			if i < len(leftValues) {
+				leftValue = leftValues[i]
+			}
+			if i < len(rightValues) {
+				rightValue = rightValues[i]
+			}
+			compare(record, i+1, leftValue, rightValue)
+		}
+	}
+	formatScores := func(scores []PedScore) []string {
+		formatted := make([]string, len(scores))
+		for i, pedScore := range scores {
+			formatted[i] = formatPedScore(pedScore)
+		}
+		return formatted
+	}
+	formatEvents := func(events []PedEvent) []string {
+		formatted := make([]string, len(events))
+		for i, pedEvent := range events {
+			formatted[i] = FormatPedEvent(pedEvent)
+		}
+		return formatted
+	}
+	formatFrameTable := func(frameTable *PedFrameTable) (string, []string) {
+		if frameTable == nil {
+			return "", []string{}
+		}
+		formatted := make([]string, len(frameTable.Runs))
+		for i, run := range frameTable.Runs {
+			formatted[i] = fmt.Sprintf("%d:%d:%d", run.Frame, run.Score, run.Note)
+		}
+		return fmt.Sprint(frameTable.Fps), formatted
+	}
+
+	compareLists("s", formatScores(left.Scores), formatScores(right.Scores))
+	compareLists("e", formatEvents(left.Events), formatEvents(right.Events))
+	leftFps, leftRuns := formatFrameTable(left.FrameTable)
+	rightFps, rightRuns := formatFrameTable(right.FrameTable)
+	compare("r", 0, leftFps, rightFps)
+	compareLists("i", leftRuns, rightRuns)
+
+	return differences
+}
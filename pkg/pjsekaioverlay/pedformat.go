@@ -0,0 +1,317 @@
+package pjsekaioverlay
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// pedファイルの形式のバージョン。形式を変えたら上げる。docs/ped.mdも参照。
+const PedFormatVersion = 3
+
+type PedFile struct {
+	// f|。0は形式のバージョンが書かれていない古いファイル
+	FormatVersion int
+	// p|
+	Assets string
+	// a|
+	Ap bool
+	// v|
+	Version string
+	// u|
+	Timestamp int64
+	// l|
+	LifeMax int
+	// s|
+	Scores []PedScore
+	// e|
+	Events []PedEvent
+	// r|とi|。書き込まれていない場合はnil
+	FrameTable *PedFrameTable
+}
+
+type PedScore struct {
+	Time     float64
+	Score    int
+	Delta    int
+	BarWidth float64
+	Rank     string
+	Combo    int
+	Life     int
+}
+
+// pedファイルに書き込む小数。-0は0にして、同じ値が常に同じ文字列になるようにする。
+func formatPedFloat(value float64) string {
+	formatted := strconv.FormatFloat(value, 'f', 6, 64)
+	if formatted == "-0.000000" {
+		return "0.000000"
+	}
+	return formatted
+}
+
+func formatPedScore(pedScore PedScore) string {
+	return strings.Join([]string{
+		formatPedFloat(pedScore.Time),
+		strconv.Itoa(pedScore.Score),
+		strconv.Itoa(pedScore.Delta),
+		formatPedFloat(pedScore.BarWidth),
+		pedScore.Rank,
+		strconv.Itoa(pedScore.Combo),
+		strconv.Itoa(pedScore.Life),
+	}, ":")
+}
+
+func FormatPedEvent(pedEvent PedEvent) string {
+	return strings.Join(append([]string{formatPedFloat(pedEvent.Time), pedEvent.Type}, pedEvent.Args...), ":")
+}
+
+type PedLine struct {
+	LineNumber int
+	Header     string
+	Data       string
+}
+
+type PedReader struct {
+	scanner    *bufio.Scanner
+	lineNumber int
+}
+
+func NewPedReader(reader io.Reader) *PedReader {
+	return &PedReader{scanner: bufio.NewScanner(reader)}
+}
+
+// 次のレコードを返す。空行は読み飛ばし、終わりに達したらio.EOFを返す。
+func (reader *PedReader) Next() (PedLine, error) {
+	for reader.scanner.Scan() {
+		reader.lineNumber++
+		line := strings.TrimRight(reader.scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		header, data, found := strings.Cut(line, "|")
+		if !found || header == "" {
+			return PedLine{}, NewError(ErrorDecode, nil, "error.pedLine", reader.lineNumber)
+		}
+		return PedLine{LineNumber: reader.lineNumber, Header: header, Data: data}, nil
+	}
+	if err := reader.scanner.Err(); err != nil {
+		return PedLine{}, NewError(ErrorFilesystem, err, "error.pedRead", err)
+	}
+	return PedLine{}, io.EOF
+}
+
+// 形式のバージョン0のファイルは「時間:スコア:増分:バーの割合:ランク:フレームの番号」の形式で、
+// スコアと増分が小数で書かれている。フレームの番号はコンボではないので読まない。
+func parsePedScore(line PedLine, formatVersion int) (PedScore, error) {
+	fields := strings.Split(line.Data, ":")
+	// 形式のバージョン0のファイルにはライフが無い
+	if (formatVersion == 0 && len(fields) != 6) || (len(fields) != 6 && len(fields) != 7) {
+		return PedScore{}, NewError(ErrorDecode, nil, "error.pedScore", line.LineNumber)
+	}
+
+	var pedScore PedScore
+	var errs []error
+	parseFloat := func(value string) float64 {
+		parsed, err := strconv.ParseFloat(value, 64)
+		errs = append(errs, err)
+		return parsed
+	}
+	parseInt := func(value string) int {
+		parsed, err := strconv.Atoi(value)
+		errs = append(errs, err)
+		return parsed
+	}
+	pedScore.Time = parseFloat(fields[0])
+	pedScore.BarWidth = parseFloat(fields[3])
+	pedScore.Rank = fields[4]
+	if formatVersion == 0 {
+		pedScore.Score = int(parseFloat(fields[1]))
+		pedScore.Delta = int(parseFloat(fields[2]))
+	} else {
+		pedScore.Score = parseInt(fields[1])
+		pedScore.Delta = parseInt(fields[2])
+		pedScore.Combo = parseInt(fields[5])
+	}
+	if len(fields) == 7 {
+		pedScore.Life = parseInt(fields[6])
+	}
+	if err := errors.Join(errs...); err != nil {
+		return PedScore{}, NewError(ErrorDecode, err, "error.pedScoreCause", line.LineNumber, err)
+	}
+	if !strings.Contains("abcds", pedScore.Rank) || len(pedScore.Rank) != 1 {
+		return PedScore{}, NewError(ErrorDecode, nil, "error.pedRank", line.LineNumber, pedScore.Rank)
+	}
+
+	return pedScore, nil
+}
+
+func parsePedEvent(line PedLine) (PedEvent, error) {
+	fields := strings.Split(line.Data, ":")
+	if len(fields) < 2 || fields[1] == "" {
+		return PedEvent{}, NewError(ErrorDecode, nil, "error.pedEvent", line.LineNumber)
+	}
+	time, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return PedEvent{}, NewError(ErrorDecode, err, "error.pedEventCause", line.LineNumber, err)
+	}
+
+	return PedEvent{Time: time, Type: fields[1], Args: fields[2:]}, nil
+}
+
+func parsePedFrameRun(line PedLine) (PedFrameRun, error) {
+	fields := strings.Split(line.Data, ":")
+	if len(fields) != 3 {
+		return PedFrameRun{}, NewError(ErrorDecode, nil, "error.pedFrameTable", line.LineNumber)
+	}
+	values := make([]int, len(fields))
+	for i, field := range fields {
+		value, err := strconv.Atoi(field)
+		// 最初のノーツの時間が負の場合、フレームは負になる
+		if err != nil || (i > 0 && value < 0) {
+			return PedFrameRun{}, NewError(ErrorDecode, nil, "error.pedFrameTable", line.LineNumber)
+		}
+		values[i] = value
+	}
+
+	return PedFrameRun{Frame: values[0], Score: values[1], Note: values[2]}, nil
+}
+
+func ParsePed(reader io.Reader) (PedFile, error) {
+	pedReader := NewPedReader(reader)
+	pedFile := PedFile{Scores: []PedScore{}, Events: []PedEvent{}}
+	// fレコードが無いファイルはバージョン0として読むが、ある場合は最初のレコードでなければならない
+	firstRecord := true
+	for {
+		line, err := pedReader.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return PedFile{}, err
+		}
+		isFirstRecord := firstRecord
+		firstRecord = false
+
+		switch line.Header {
+		case "f":
+			if !isFirstRecord {
+				return PedFile{}, NewError(ErrorDecode, nil, "error.pedFormatNotFirst", line.LineNumber)
+			}
+			pedFile.FormatVersion, err = strconv.Atoi(line.Data)
+			if err != nil {
+				return PedFile{}, NewError(ErrorDecode, nil, "error.pedFormatVersion", line.Data)
+			}
+			if pedFile.FormatVersion > PedFormatVersion {
+				return PedFile{}, NewError(ErrorDecode, nil, "error.pedUnsupportedFormat", pedFile.FormatVersion)
+			}
+		case "p":
+			pedFile.Assets = line.Data
+		case "a":
+			pedFile.Ap = line.Data == "true"
+		case "v":
+			pedFile.Version = line.Data
+		case "u":
+			pedFile.Timestamp, err = strconv.ParseInt(line.Data, 10, 64)
+			if err != nil {
+				return PedFile{}, NewError(ErrorDecode, nil, "error.pedLine", line.LineNumber)
+			}
+		case "l":
+			pedFile.LifeMax, err = strconv.Atoi(line.Data)
+			if err != nil {
+				return PedFile{}, NewError(ErrorDecode, nil, "error.pedLine", line.LineNumber)
+			}
+		case "s":
+			pedScore, err := parsePedScore(line, pedFile.FormatVersion)
+			if err != nil {
+				return PedFile{}, err
+			}
+			pedFile.Scores = append(pedFile.Scores, pedScore)
+		case "e":
+			pedEvent, err := parsePedEvent(line)
+			if err != nil {
+				return PedFile{}, err
+			}
+			pedFile.Events = append(pedFile.Events, pedEvent)
+		case "r":
+			fps, err := strconv.ParseFloat(line.Data, 64)
+			if err != nil || fps <= 0 {
+				return PedFile{}, NewError(ErrorDecode, nil, "error.pedFrameRate", line.LineNumber)
+			}
+			if pedFile.FrameTable == nil {
+				pedFile.FrameTable = &PedFrameTable{Runs: []PedFrameRun{}}
+			}
+			pedFile.FrameTable.Fps = fps
+		case "i":
+			if pedFile.FrameTable == nil {
+				return PedFile{}, NewError(ErrorDecode, nil, "error.pedNoFrameRate", line.LineNumber)
+			}
+			pedFrameRun, err := parsePedFrameRun(line)
+			if err != nil {
+				return PedFile{}, err
+			}
+			pedFile.FrameTable.Runs = append(pedFile.FrameTable.Runs, pedFrameRun)
+		}
+	}
+
+	return pedFile, nil
+}
+
+func ParsePedFile(path string) (PedFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return PedFile{}, NewError(ErrorFilesystem, err, "error.pedOpen", err)
+	}
+	defer file.Close()
+
+	return ParsePed(file)
+}
+
+func WritePed(writer io.Writer, pedFile PedFile) error {
+	bufferedWriter := bufio.NewWriter(writer)
+
+	lines := []string{
+		fmt.Sprintf("f|%d", pedFile.FormatVersion),
+		fmt.Sprintf("p|%s", pedFile.Assets),
+		fmt.Sprintf("a|%s", strconv.FormatBool(pedFile.Ap)),
+		fmt.Sprintf("v|%s", pedFile.Version),
+		fmt.Sprintf("u|%d", pedFile.Timestamp),
+		fmt.Sprintf("l|%d", pedFile.LifeMax),
+	}
+	for _, line := range lines {
+		if _, err := bufferedWriter.WriteString(line + "\n"); err != nil {
+			return NewError(ErrorFilesystem, err, "error.writeFile", err)
+		}
+	}
+	for _, pedScore := range pedFile.Scores {
+		if _, err := bufferedWriter.WriteString("s|" + formatPedScore(pedScore) + "\n"); err != nil {
+			return NewError(ErrorFilesystem, err, "error.writeFile", err)
+		}
+	}
+
+	for _, pedEvent := range pedFile.Events {
+		if _, err := bufferedWriter.WriteString("e|" + FormatPedEvent(pedEvent) + "\n"); err != nil {
+			return NewError(ErrorFilesystem, err, "error.writeFile", err)
+		}
+	}
+
+	if pedFile.FrameTable != nil {
+		_, err := fmt.Fprintf(bufferedWriter, "r|%s\n", strconv.FormatFloat(pedFile.FrameTable.Fps, 'f', -1, 64))
+		if err != nil {
+			return NewError(ErrorFilesystem, err, "error.writeFile", err)
+		}
+		for _, run := range pedFile.FrameTable.Runs {
+			_, err := fmt.Fprintf(bufferedWriter, "i|%d:%d:%d\n", run.Frame, run.Score, run.Note)
+			if err != nil {
+				return NewError(ErrorFilesystem, err, "error.writeFile", err)
+			}
+		}
+	}
+
+	if err := bufferedWriter.Flush(); err != nil {
+		return NewError(ErrorFilesystem, err, "error.writeFile", err)
+	}
+	return nil
+}
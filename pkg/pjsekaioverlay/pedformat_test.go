@@ -0,0 +1,50 @@
+package pjsekaioverlay
+
+import (
+	"strings"
+	"testing"
+)
+
+// 形式のバージョンが付く前のpjsekai-overlayが書き込んだpedファイル
+const legacyPed = `p|C:/pjsekai-overlay/assets
+a|true
+v|v0.5.1
+u|1690000000
+s|0.000000:0.000000:0.000000:0.000000:d:0
+s|1.250000:3312.541234:3312.541234:0.000000:d:1
+s|1.500000:6625.082468:3312.541234:0.026000:d:2
+`
+
+func TestParseLegacyPed(t *testing.T) {
+	pedFile, err := ParsePed(strings.NewReader(legacyPed))
+	if err != nil {
+		t.Fatalf("ParsePed: %v", err)
+	}
+	if pedFile.FormatVersion != 0 {
+		t.Errorf("FormatVersion = %d, want 0", pedFile.FormatVersion)
+	}
+	if pedFile.Assets != "C:/pjsekai-overlay/assets" || !pedFile.Ap || pedFile.Version != "v0.5.1" || pedFile.Timestamp != 1690000000 {
+		t.Errorf("header = %+v", pedFile)
+	}
+
+	want := []PedScore{
+		{Time: 0, Score: 0, Delta: 0, BarWidth: 0, Rank: "d"},
+		{Time: 1.25, Score: 3312, Delta: 3312, BarWidth: 0, Rank: "d"},
+		{Time: 1.5, Score: 6625, Delta: 3312, BarWidth: 0.026, Rank: "d"},
+	}
+	if len(pedFile.Scores) != len(want) {
+		t.Fatalf("len(Scores) = %d, want %d", len(pedFile.Scores), len(want))
+	}
+	for i, pedScore := range pedFile.Scores {
+		if pedScore != want[i] {
+			t.Errorf("Scores[%d] = %+v, want %+v", i, pedScore, want[i])
+		}
+	}
+}
+
+func TestParsePedFormatVersionNotFirst(t *testing.T) {
+	_, err := ParsePed(strings.NewReader("v|v1.0.0\nf|3\n"))
+	if err == nil {
+		t.Fatal("ParsePed accepted f| after another record")
+	}
+}
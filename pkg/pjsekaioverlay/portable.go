@@ -0,0 +1,175 @@
+package pjsekaioverlay
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/transform"
+)
+
+// ポータブルモードでアセットをコピーする、出力先ディレクトリの中のディレクトリ名
+const PortableAssetsDir = "assets"
+
+// exoファイルとsekai.objが使うアセットの一部。doctorでの確認に使う。
+var REQUIRED_ASSETS = []string{
+	"ap.mp4",
+	"append_bg.png",
+	"life.png",
+	"master_bg.png",
+	"perfect.png",
+	"start_grad.png",
+	"combo/pt.png",
+	"combo/nt.png",
+	"score/bar.png",
+	"score/bg.png",
+	"score/fg.png",
+	"score/digit/0.png",
+}
+
+// アセットのディレクトリを出力先ディレクトリにコピーし、コピー先のパスを返す。
+// 同じサイズのファイルが既にある場合はコピーしない。
+func CopyAssets(assets string, destDir string) (string, error) {
+	dest := filepath.Join(destDir, PortableAssetsDir)
+	err := filepath.WalkDir(assets, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relativePath, err := filepath.Rel(assets, path)
+		if err != nil {
+			return err
+		}
+		destPath := filepath.Join(dest, relativePath)
+		if entry.IsDir() {
+			return os.MkdirAll(destPath, 0755)
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if destInfo, err := os.Stat(destPath); err == nil && destInfo.Size() == info.Size() {
+			return nil
+		}
+		return copyFile(path, destPath)
+	})
+	if err != nil {
+		return "", NewError(ErrorFilesystem, err, "error.copyAssets", err)
+	}
+
+	return dest, nil
+}
+
+func copyFile(src string, dest string) error {
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+
+	destFile, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(destFile, srcFile); err != nil {
+		destFile.Close()
+		return err
+	}
+	return destFile.Close()
+}
+
+// pedファイルのp|に書き込むパス。baseDirの中にあれば相対パスにする。
+func PedAssetsPath(assets string, baseDir string) string {
+	relativePath, err := filepath.Rel(baseDir, assets)
+	if err != nil || relativePath == ".." || strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+		return assets
+	}
+	return filepath.ToSlash(relativePath)
+}
+
+// pedファイルのp|を書き換える。他の行はそのまま残す。
+func RelocatePedFile(path string, assets string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return NewError(ErrorFilesystem, err, "error.pedOpen", err)
+	}
+
+	found := false
+	lines := strings.SplitAfter(string(data), "\n")
+	for i, line := range lines {
+		content := strings.TrimRight(line, "\r\n")
+		if !strings.HasPrefix(content, "p|") {
+			continue
+		}
+		lines[i] = "p|" + assets + line[len(content):]
+		found = true
+		break
+	}
+	if !found {
+		return NewError(ErrorDecode, nil, "error.pedNoAssets")
+	}
+
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "")), 0644); err != nil {
+		return NewError(ErrorFilesystem, err, "error.writeFile", err)
+	}
+	return nil
+}
+
+var exoPedPathPattern = regexp.MustCompile(`param=file="(.*)\\\\data\.ped"`)
+
+// exoファイルの中の出力先ディレクトリとアセットのディレクトリのパスを書き換える。
+// 元の出力先ディレクトリはpedファイルを指定している行から、元のアセットのディレクトリはoldAssetsから判断する。
+func RelocateExoFile(path string, destDir string, oldAssets string, assets string) error {
+	rawExo, err := os.ReadFile(path)
+	if err != nil {
+		return NewError(ErrorFilesystem, err, "error.exoOpen", err)
+	}
+	decodedExo, err := io.ReadAll(transform.NewReader(bytes.NewReader(rawExo), japanese.ShiftJIS.NewDecoder()))
+	if err != nil {
+		return NewError(ErrorDecode, err, "error.decode", err)
+	}
+	exo := string(decodedExo)
+
+	match := exoPedPathPattern.FindStringSubmatch(exo)
+	if match == nil {
+		return NewError(ErrorDecode, nil, "error.exoNoPed")
+	}
+	oldDestDir := match[1]
+	oldExoAssets := strings.ReplaceAll(oldAssets, "\\", "/")
+	if !filepath.IsAbs(oldAssets) {
+		oldExoAssets = oldDestDir + "/" + oldExoAssets
+	}
+
+	// WriteExoFilesと同じく、区切り文字は/で書き込む
+	replacements := [][2]string{
+		{"file=" + oldExoAssets + "\\", "file=" + filepath.ToSlash(assets) + "\\"},
+		{"file=" + oldDestDir + "\\", "file=" + filepath.ToSlash(destDir) + "\\"},
+		{`param=file="` + oldDestDir + `\\data.ped"`, `param=file="` + filepath.ToSlash(destDir) + `\\data.ped"`},
+	}
+	lines := strings.Split(exo, "\r\n")
+	for i, line := range lines {
+		// アセットが出力先ディレクトリの中にある場合もあるので、最初に一致したものだけを置き換える
+		for _, replacement := range replacements {
+			if strings.HasPrefix(line, replacement[0]) {
+				lines[i] = replacement[1] + strings.TrimPrefix(line, replacement[0])
+				break
+			}
+		}
+	}
+
+	encodedExo, err := io.ReadAll(transform.NewReader(
+		strings.NewReader(strings.Join(lines, "\r\n")), japanese.ShiftJIS.NewEncoder()))
+	if err != nil {
+		return NewError(ErrorEncoding, err, "error.encode", err)
+	}
+	if err := os.WriteFile(path, encodedExo, 0644); err != nil {
+		return NewError(ErrorFilesystem, err, "error.writeFile", err)
+	}
+
+	return nil
+}
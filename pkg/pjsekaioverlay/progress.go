@@ -0,0 +1,106 @@
+package pjsekaioverlay
+
+import (
+	"io"
+	"net/http"
+	"time"
+)
+
+// ダウンロードの進捗。
+type DownloadProgress struct {
+	// 「cover」「background」など
+	Name string
+	// ダウンロードしたバイト数
+	Bytes int64
+	// 全体のバイト数。不明な場合は-1
+	Total   int64
+	Elapsed time.Duration
+}
+
+// 1秒あたりのバイト数。
+func (progress DownloadProgress) Rate() float64 {
+	if progress.Elapsed <= 0 {
+		return 0
+	}
+	return float64(progress.Bytes) / progress.Elapsed.Seconds()
+}
+
+// 残り時間。全体のバイト数が不明な場合などはfalse。
+func (progress DownloadProgress) Eta() (time.Duration, bool) {
+	rate := progress.Rate()
+	if progress.Total < 0 || rate <= 0 {
+		return 0, false
+	}
+	remaining := float64(progress.Total-progress.Bytes) / rate
+	return time.Duration(remaining * float64(time.Second)), true
+}
+
+// ダウンロードの進捗の通知先。
+type Progress interface {
+	// ダウンロード中に一定の間隔で呼ばれる。
+	Downloading(progress DownloadProgress)
+	// 全て読み込んだ時か、途中で閉じられた時に呼ばれる。
+	Downloaded(progress DownloadProgress)
+}
+
+// 進捗を通知する間隔
+const progressInterval = 100 * time.Millisecond
+
+var currentProgress Progress
+
+// nilの場合は通知しない。
+func SetProgress(progress Progress) {
+	currentProgress = progress
+}
+
+type progressReader struct {
+	body       io.ReadCloser
+	progress   DownloadProgress
+	startedAt  time.Time
+	reportedAt time.Time
+	finished   bool
+}
+
+func (reader *progressReader) Read(p []byte) (int, error) {
+	n, err := reader.body.Read(p)
+	reader.progress.Bytes += int64(n)
+	now := time.Now()
+	reader.progress.Elapsed = now.Sub(reader.startedAt)
+	if err == io.EOF {
+		reader.finish()
+	} else if now.Sub(reader.reportedAt) >= progressInterval {
+		reader.reportedAt = now
+		currentProgress.Downloading(reader.progress)
+	}
+	return n, err
+}
+
+func (reader *progressReader) finish() {
+	if !reader.finished {
+		reader.finished = true
+		currentProgress.Downloaded(reader.progress)
+	}
+}
+
+func (reader *progressReader) Close() error {
+	reader.finish()
+	return reader.body.Close()
+}
+
+// http.Getと同じだが、本文を読み込む間に進捗を通知する。
+func httpGet(name string, url string) (*http.Response, error) {
+	resp, err := http.Get(url)
+	if err != nil || currentProgress == nil || resp.StatusCode != 200 {
+		return resp, err
+	}
+	startedAt := time.Now()
+	reader := &progressReader{
+		body:      resp.Body,
+		progress:  DownloadProgress{Name: name, Total: resp.ContentLength},
+		startedAt: startedAt,
+	}
+	currentProgress.Downloading(reader.progress)
+	reader.reportedAt = startedAt
+	resp.Body = reader
+	return resp, nil
+}
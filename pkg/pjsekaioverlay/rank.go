@@ -0,0 +1,171 @@
+package pjsekaioverlay
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// ボーダー = Base + PerRating * (レベル - BaseRating)
+type RankBorderFormula struct {
+	Base      float64 `json:"base"`
+	PerRating float64 `json:"perRating"`
+}
+
+type BarSegment struct {
+	Start float64 `json:"start"`
+	Width float64 `json:"width"`
+}
+
+type RankBorders struct {
+	C float64 `json:"c"`
+	B float64 `json:"b"`
+	A float64 `json:"a"`
+	S float64 `json:"s"`
+	// バーが満タンになるスコア
+	Max float64 `json:"max"`
+}
+
+type RankTable struct {
+	BaseRating int `json:"baseRating"`
+	MinRating  int `json:"minRating"`
+	MaxRating  int `json:"maxRating"`
+
+	C   RankBorderFormula `json:"c"`
+	B   RankBorderFormula `json:"b"`
+	A   RankBorderFormula `json:"a"`
+	S   RankBorderFormula `json:"s"`
+	Max RankBorderFormula `json:"max"`
+
+	// d, c, b, a, sの順のバーの区間（ピクセル）
+	BarSegments [5]BarSegment `json:"barSegments"`
+	BarWidth    float64       `json:"barWidth"`
+}
+
+// 161, 215, 267, 320, 357
+var DEFAULT_BAR_SEGMENTS = [5]BarSegment{
+	{Start: 0, Width: 160},
+	{Start: 161, Width: 54},
+	{Start: 215, Width: 53},
+	{Start: 267, Width: 53},
+	{Start: 320, Width: 37},
+}
+
+const DefaultBarWidth = 357
+
+var SOLO_RANK_TABLE = RankTable{
+	BaseRating: 5,
+	MinRating:  5,
+	MaxRating:  40,
+
+	C:   RankBorderFormula{Base: 20000, PerRating: 100},
+	B:   RankBorderFormula{Base: 400000, PerRating: 2000},
+	A:   RankBorderFormula{Base: 840000, PerRating: 4200},
+	S:   RankBorderFormula{Base: 1040000, PerRating: 5200},
+	Max: RankBorderFormula{Base: 1200000, PerRating: 4100},
+
+	BarSegments: DEFAULT_BAR_SEGMENTS,
+	BarWidth:    DefaultBarWidth,
+}
+
+// ボーダーはライブのモードとレベルで決まり、APPENDの譜面もソロライブではレベルの式のまま。
+var RANK_TABLES = map[string]RankTable{
+	"solo":   SOLO_RANK_TABLE,
+	"append": SOLO_RANK_TABLE,
+}
+
+// ボーダーの式が分かっていないモード。値を推測せず、JSONでカスタムのテーブルを指定してもらう。
+var UNAVAILABLE_RANK_TABLES = map[string]bool{
+	"multi":     true,
+	"challenge": true,
+}
+
+// 全てのレベルで、ボーダーが0より大きく、C < B < A < S < Maxになっているかを確認する。
+// 式は一次式なので、最小と最大のレベルだけを確認すればよい。
+func (table RankTable) validate() error {
+	if table.BarWidth <= 0 {
+		return NewError(ErrorDecode, nil, "error.rankTableBarWidth")
+	}
+	if table.MinRating > table.MaxRating {
+		return NewError(ErrorDecode, nil, "error.rankTableRating", table.MinRating, table.MaxRating)
+	}
+	for _, rating := range []int{table.MinRating, table.MaxRating} {
+		borders := table.Borders(rating)
+		if !(0 < borders.C && borders.C < borders.B && borders.B < borders.A && borders.A < borders.S && borders.S < borders.Max) {
+			return NewError(ErrorDecode, nil, "error.rankTableBorders", rating)
+		}
+	}
+	return nil
+}
+
+// nameがRANK_TABLESに無い場合は、カスタムのテーブルのJSONファイルのパスとして扱う。
+func GetRankTable(name string) (RankTable, error) {
+	if table, ok := RANK_TABLES[name]; ok {
+		return table, nil
+	}
+	if UNAVAILABLE_RANK_TABLES[name] {
+		return RankTable{}, NewError(ErrorDecode, nil, "error.rankTableUnavailable", name)
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return RankTable{}, NewError(ErrorFilesystem, err, "error.rankTableOpen", err)
+	}
+	table := SOLO_RANK_TABLE
+	err = json.Unmarshal(data, &table)
+	if err != nil {
+		return RankTable{}, NewError(ErrorDecode, err, "error.rankTableRead", err)
+	}
+	if err := table.validate(); err != nil {
+		return RankTable{}, err
+	}
+
+	return table, nil
+}
+
+func (table RankTable) Borders(rating int) RankBorders {
+	if rating < table.MinRating {
+		rating = table.MinRating
+	} else if rating > table.MaxRating {
+		rating = table.MaxRating
+	}
+	level := float64(rating - table.BaseRating)
+
+	return RankBorders{
+		C:   table.C.Base + table.C.PerRating*level,
+		B:   table.B.Base + table.B.PerRating*level,
+		A:   table.A.Base + table.A.PerRating*level,
+		S:   table.S.Base + table.S.PerRating*level,
+		Max: table.Max.Base + table.Max.PerRating*level,
+	}
+}
+
+// ランクと、バーの埋まっている割合（0〜1）を返す。
+func (table RankTable) Rank(score int, rating int) (string, float64) {
+	borders := table.Borders(rating)
+	value := float64(score)
+	segments := table.BarSegments
+
+	rank := "d"
+	scoreX := 0.0
+	if value >= borders.Max {
+		rank = "s"
+		scoreX = table.BarWidth
+	} else if value >= borders.S {
+		rank = "s"
+		scoreX = ((value-borders.S)/(borders.Max-borders.S))*segments[4].Width + segments[4].Start
+	} else if value >= borders.A {
+		rank = "a"
+		scoreX = ((value-borders.A)/(borders.S-borders.A))*segments[3].Width + segments[3].Start
+	} else if value >= borders.B {
+		rank = "b"
+		scoreX = ((value-borders.B)/(borders.A-borders.B))*segments[2].Width + segments[2].Start
+	} else if value >= borders.C {
+		rank = "c"
+		scoreX = ((value-borders.C)/(borders.B-borders.C))*segments[1].Width + segments[1].Start
+	} else {
+		rank = "d"
+		scoreX = (value/borders.C)*segments[0].Width + segments[0].Start
+	}
+
+	return rank, scoreX / table.BarWidth
+}
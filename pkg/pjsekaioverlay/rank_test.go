@@ -0,0 +1,44 @@
+package pjsekaioverlay
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSoloRankTableIsValid(t *testing.T) {
+	if err := SOLO_RANK_TABLE.validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetRankTableRejectsInvalidBorders(t *testing.T) {
+	tables := map[string]any{
+		"zeroC":      map[string]any{"c": map[string]float64{"base": 0, "perRating": 0}},
+		"sameBorder": map[string]any{"b": map[string]float64{"base": 840000, "perRating": 4200}},
+		"decreasing": map[string]any{"max": map[string]float64{"base": 1000000, "perRating": 0}},
+	}
+	dir := t.TempDir()
+	for name, table := range tables {
+		data, err := json.Marshal(table)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, name+".json")
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := GetRankTable(path); err == nil {
+			t.Errorf("%s: GetRankTable accepted an invalid table", name)
+		}
+	}
+}
+
+func TestGetRankTableUnavailablePresets(t *testing.T) {
+	for name := range UNAVAILABLE_RANK_TABLES {
+		if _, err := GetRankTable(name); err == nil {
+			t.Errorf("%s: GetRankTable returned a table", name)
+		}
+	}
+}
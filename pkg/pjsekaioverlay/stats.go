@@ -0,0 +1,187 @@
+package pjsekaioverlay
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/sevenc-nanashi/pjsekai-overlay/pkg/sonolus"
+)
+
+type ArchetypeCount struct {
+	Archetype string  `json:"archetype"`
+	Count     int     `json:"count"`
+	Weight    float64 `json:"weight"`
+}
+
+type ChartStats struct {
+	Title  string `json:"title"`
+	Author string `json:"author"`
+	Rating int    `json:"rating"`
+
+	// ダメージノーツと、中継点などの重みが1未満のノーツを除いたノーツ数
+	Notes         int     `json:"notes"`
+	ComboNotes    int     `json:"comboNotes"`
+	WeightedNotes float64 `json:"weightedNotes"`
+	// 最初のノーツから最後のノーツまでの秒数
+	Duration float64 `json:"duration"`
+	MaxCombo int     `json:"maxCombo"`
+	// Notesと同じノーツで数えた、1秒間のノーツ数の最大値
+	PeakNps    int `json:"peakNps"`
+	FinalScore int `json:"finalScore"`
+
+	Archetypes []ArchetypeCount `json:"archetypes"`
+	// Notesと同じノーツで数えた1秒毎のノーツ数。0番目は0秒から1秒まで
+	Density []int `json:"density"`
+}
+
+// ライフと同じく、ダメージノーツと重みが1未満のノーツは譜面のノーツとして数えない。
+func isChartNote(archetype string, weight float64) bool {
+	return !isDamageNote(archetype) && weight >= 1
+}
+
+func CalculateStats(levelInfo sonolus.LevelInfo, scoreData ScoreResult) ChartStats {
+	stats := ChartStats{
+		Title:      levelInfo.Title,
+		Author:     levelInfo.Author,
+		Rating:     levelInfo.Rating,
+		FinalScore: scoreData.Breakdown.Total,
+		Archetypes: []ArchetypeCount{},
+		Density:    []int{},
+	}
+	if len(scoreData.Notes) == 0 {
+		return stats
+	}
+
+	// 譜面のノーツ数として数えるノーツ
+	chartNotes := []ScoredNote{}
+	archetypeCounts := map[string]*ArchetypeCount{}
+	for _, note := range scoreData.Notes {
+		if isChartNote(note.Archetype, note.Weight) {
+			chartNotes = append(chartNotes, note)
+		}
+		stats.WeightedNotes += note.Weight
+		if countsCombo(note.Archetype, note.Weight) {
+			stats.ComboNotes++
+		}
+		if note.Combo > stats.MaxCombo {
+			stats.MaxCombo = note.Combo
+		}
+		if archetypeCount, ok := archetypeCounts[note.Archetype]; ok {
+			archetypeCount.Count++
+		} else {
+			archetypeCounts[note.Archetype] = &ArchetypeCount{
+				Archetype: note.Archetype,
+				Count:     1,
+				Weight:    note.Weight,
+			}
+		}
+	}
+	for _, archetypeCount := range archetypeCounts {
+		stats.Archetypes = append(stats.Archetypes, *archetypeCount)
+	}
+	sort.Slice(stats.Archetypes, func(i, j int) bool {
+		if stats.Archetypes[i].Count != stats.Archetypes[j].Count {
+			return stats.Archetypes[i].Count > stats.Archetypes[j].Count
+		}
+		return stats.Archetypes[i].Archetype < stats.Archetypes[j].Archetype
+	})
+
+	firstTime := scoreData.Notes[0].Time
+	lastTime := scoreData.Notes[len(scoreData.Notes)-1].Time
+	stats.Duration = lastTime - firstTime
+
+	stats.Notes = len(chartNotes)
+
+	// 1秒間の窓を動かして、その中にあるノーツ数の最大値を取る
+	windowStart := 0
+	for i, note := range chartNotes {
+		for note.Time-chartNotes[windowStart].Time >= 1 {
+			windowStart++
+		}
+		if i-windowStart+1 > stats.PeakNps {
+			stats.PeakNps = i - windowStart + 1
+		}
+	}
+
+	if lastTime >= 0 {
+		stats.Density = make([]int, int(math.Floor(lastTime))+1)
+		for _, note := range chartNotes {
+			if note.Time < 0 {
+				continue
+			}
+			stats.Density[int(math.Floor(note.Time))]++
+		}
+	}
+
+	return stats
+}
+
+func formatDuration(seconds float64) string {
+	return fmt.Sprintf("%d:%06.3f", int(seconds)/60, math.Mod(seconds, 60))
+}
+
+func WriteStatsTable(writer io.Writer, stats ChartStats) error {
+	tableWriter := tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+	fmt.Fprint(tableWriter, T("stats.title", stats.Title))
+	fmt.Fprint(tableWriter, T("stats.author", stats.Author))
+	fmt.Fprint(tableWriter, T("stats.rating", stats.Rating))
+	fmt.Fprint(tableWriter, T("stats.notes", stats.Notes))
+	fmt.Fprint(tableWriter, T("stats.comboNotes", stats.ComboNotes))
+	fmt.Fprint(tableWriter, T("stats.weightedNotes", stats.WeightedNotes))
+	fmt.Fprint(tableWriter, T("stats.duration", formatDuration(stats.Duration)))
+	fmt.Fprint(tableWriter, T("stats.maxCombo", stats.MaxCombo))
+	fmt.Fprint(tableWriter, T("stats.peakNps", stats.PeakNps))
+	fmt.Fprint(tableWriter, T("stats.finalScore", stats.FinalScore))
+	fmt.Fprintln(tableWriter)
+
+	fmt.Fprintln(tableWriter, T("stats.archetypeHeader"))
+	for _, archetypeCount := range stats.Archetypes {
+		fmt.Fprintf(tableWriter, "%s\t%d\t%g\n", archetypeCount.Archetype, archetypeCount.Count, archetypeCount.Weight)
+	}
+	fmt.Fprintln(tableWriter)
+
+	fmt.Fprintln(tableWriter, T("stats.densityHeader"))
+	for second, count := range stats.Density {
+		fmt.Fprintf(tableWriter, "%d\t%d\n", second, count)
+	}
+
+	return tableWriter.Flush()
+}
+
+func WriteStatsJSON(writer io.Writer, stats ChartStats) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(stats)
+}
+
+// CSVは「区分,キー,値」の3列で、区分はsummary、archetype、densityのいずれか。
+func WriteStatsCSV(writer io.Writer, stats ChartStats) error {
+	csvWriter := csv.NewWriter(writer)
+	records := [][]string{
+		{"section", "key", "value"},
+		{"summary", "title", stats.Title},
+		{"summary", "author", stats.Author},
+		{"summary", "rating", strconv.Itoa(stats.Rating)},
+		{"summary", "notes", strconv.Itoa(stats.Notes)},
+		{"summary", "comboNotes", strconv.Itoa(stats.ComboNotes)},
+		{"summary", "weightedNotes", strconv.FormatFloat(stats.WeightedNotes, 'f', -1, 64)},
+		{"summary", "duration", strconv.FormatFloat(stats.Duration, 'f', 3, 64)},
+		{"summary", "maxCombo", strconv.Itoa(stats.MaxCombo)},
+		{"summary", "peakNps", strconv.Itoa(stats.PeakNps)},
+		{"summary", "finalScore", strconv.Itoa(stats.FinalScore)},
+	}
+	for _, archetypeCount := range stats.Archetypes {
+		records = append(records, []string{"archetype", archetypeCount.Archetype, strconv.Itoa(archetypeCount.Count)})
+	}
+	for second, count := range stats.Density {
+		records = append(records, []string{"density", strconv.Itoa(second), strconv.Itoa(count)})
+	}
+
+	return csvWriter.WriteAll(records)
+}
@@ -0,0 +1,93 @@
+package pjsekaioverlay
+
+import (
+	"sort"
+
+	"github.com/sevenc-nanashi/pjsekai-overlay/pkg/sonolus"
+)
+
+// BPM変更が1つも無い譜面で使うBPM
+const DefaultBpm = 60.0
+
+type BpmChange struct {
+	Beat float64
+	Bpm  float64
+}
+
+// 拍から秒への変換表。最初のBPMは負の拍まで、最後のBPMは譜面の終わりまで続くものとし、
+// 0拍目を0秒とする。
+type TempoMap struct {
+	changes []BpmChange
+	times   []float64
+	origin  float64
+}
+
+func NewTempoMap(bpmChanges []BpmChange) TempoMap {
+	sorted := make([]BpmChange, len(bpmChanges))
+	copy(sorted, bpmChanges)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Beat < sorted[j].Beat
+	})
+
+	// 同じ拍のBPM変更は後のものを優先する
+	changes := make([]BpmChange, 0, len(sorted))
+	for _, bpmChange := range sorted {
+		if bpmChange.Bpm <= 0 {
+			continue
+		}
+		if len(changes) > 0 && changes[len(changes)-1].Beat == bpmChange.Beat {
+			changes[len(changes)-1] = bpmChange
+		} else {
+			changes = append(changes, bpmChange)
+		}
+	}
+	if len(changes) == 0 {
+		changes = append(changes, BpmChange{Beat: 0, Bpm: DefaultBpm})
+	}
+
+	times := make([]float64, len(changes))
+	for i := 1; i < len(changes); i++ {
+		times[i] = times[i-1] + (changes[i].Beat-changes[i-1].Beat)*(60/changes[i-1].Bpm)
+	}
+
+	tempoMap := TempoMap{changes: changes, times: times}
+	tempoMap.origin = tempoMap.rawTime(0)
+	return tempoMap
+}
+
+func NewTempoMapFromLevelData(levelData sonolus.LevelData) TempoMap {
+	bpmChanges := []BpmChange{}
+	for _, entity := range levelData.Entities {
+		if entity.Archetype != "#BPM_CHANGE" {
+			continue
+		}
+		beat, err := getValueFromData(entity.Data, "#BEAT")
+		if err != nil {
+			continue
+		}
+		bpm, err := getValueFromData(entity.Data, "#BPM")
+		if err != nil {
+			continue
+		}
+		bpmChanges = append(bpmChanges, BpmChange{
+			Beat: beat,
+			Bpm:  bpm,
+		})
+	}
+	return NewTempoMap(bpmChanges)
+}
+
+func (tempoMap TempoMap) rawTime(beat float64) float64 {
+	index := sort.Search(len(tempoMap.changes), func(i int) bool {
+		return tempoMap.changes[i].Beat > beat
+	}) - 1
+	if index < 0 {
+		index = 0
+	}
+	bpmChange := tempoMap.changes[index]
+	return tempoMap.times[index] + (beat-bpmChange.Beat)*(60/bpmChange.Bpm)
+}
+
+func (tempoMap TempoMap) Time(beat float64) float64 {
+	return tempoMap.rawTime(beat) - tempoMap.origin
+}
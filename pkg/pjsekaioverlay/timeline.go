@@ -0,0 +1,117 @@
+package pjsekaioverlay
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type TimelineEntry struct {
+	// 秒
+	Time float64 `json:"time"`
+	// この状態が最初に表示される動画のフレーム（sekai.objと同じく「時間 * fps < フレーム」）
+	Frame    int     `json:"frame"`
+	Score    int     `json:"score"`
+	Delta    int     `json:"delta"`
+	Combo    int     `json:"combo"`
+	Life     int     `json:"life"`
+	Rank     string  `json:"rank"`
+	BarWidth float64 `json:"barWidth"`
+}
+
+type Timeline struct {
+	Fps     float64         `json:"fps"`
+	Rating  int             `json:"rating"`
+	Borders RankBorders     `json:"borders"`
+	Entries []TimelineEntry `json:"entries"`
+}
+
+func BuildTimeline(scoreData ScoreResult, options PedOptions, fps float64) Timeline {
+	rating := options.LevelInfo.Rating
+	timeline := Timeline{
+		Fps:     fps,
+		Rating:  rating,
+		Borders: options.RankTable.Borders(rating),
+		Entries: make([]TimelineEntry, 0, len(scoreData.Frames)),
+	}
+
+	lastScore := 0
+	for _, frame := range scoreData.Frames {
+		rank, barWidth := options.RankTable.Rank(frame.Score, rating)
+		timeline.Entries = append(timeline.Entries, TimelineEntry{
+			Time:     frame.Time,
+			Frame:    int(math.Floor(roundPedTime(frame.Time)*fps)) + 1,
+			Score:    frame.Score,
+			Delta:    frame.Score - lastScore,
+			Combo:    frame.Combo,
+			Life:     frame.Life,
+			Rank:     rank,
+			BarWidth: barWidth,
+		})
+		lastScore = frame.Score
+	}
+
+	return timeline
+}
+
+func WriteTimelineJSON(writer io.Writer, timeline Timeline) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(timeline)
+}
+
+// ランクのボーダーはCSVには含まれない。
+func WriteTimelineCSV(writer io.Writer, timeline Timeline) error {
+	csvWriter := csv.NewWriter(writer)
+	records := [][]string{
+		{"time", "frame", "score", "delta", "combo", "life", "rank", "barWidth"},
+	}
+	for _, entry := range timeline.Entries {
+		records = append(records, []string{
+			formatPedFloat(entry.Time),
+			strconv.Itoa(entry.Frame),
+			strconv.Itoa(entry.Score),
+			strconv.Itoa(entry.Delta),
+			strconv.Itoa(entry.Combo),
+			strconv.Itoa(entry.Life),
+			entry.Rank,
+			formatPedFloat(entry.BarWidth),
+		})
+	}
+
+	return csvWriter.WriteAll(records)
+}
+
+// 拡張子（.json / .csv）で形式を決めて書き込む。
+func WriteTimelineFile(scoreData ScoreResult, path string, options PedOptions, fps float64) error {
+	var write func(io.Writer, Timeline) error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		write = WriteTimelineJSON
+	case ".csv":
+		write = WriteTimelineCSV
+	default:
+		return Errorf("error.timelineFormat", filepath.Ext(path))
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return NewError(ErrorFilesystem, err, "error.createFile", err)
+	}
+
+	err = write(file, BuildTimeline(scoreData, options, fps))
+	if err != nil {
+		file.Close()
+		return NewError(ErrorFilesystem, err, "error.writeFile", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return NewError(ErrorFilesystem, err, "error.writeFile", err)
+	}
+	return nil
+}
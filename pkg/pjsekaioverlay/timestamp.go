@@ -0,0 +1,42 @@
+package pjsekaioverlay
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// https://reproducible-builds.org/specs/source-date-epoch/
+const SourceDateEpochEnv = "SOURCE_DATE_EPOCH"
+
+// pedファイルに書き込む時刻を決める。
+// specは「now」（現在の時刻）、「source」（環境変数SOURCE_DATE_EPOCH）、またはUNIX時間。
+// deterministicの場合、「now」はSOURCE_DATE_EPOCH、それも無ければ0として扱う。
+func ResolveTimestamp(spec string, deterministic bool) (int64, error) {
+	switch spec {
+	case "", "now":
+		if !deterministic {
+			return time.Now().Unix(), nil
+		}
+		if _, ok := os.LookupEnv(SourceDateEpochEnv); !ok {
+			return 0, nil
+		}
+		return ResolveTimestamp("source", deterministic)
+	case "source":
+		value, ok := os.LookupEnv(SourceDateEpochEnv)
+		if !ok {
+			return 0, Errorf("error.sourceDateEpochUnset", SourceDateEpochEnv)
+		}
+		timestamp, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return 0, Errorf("error.sourceDateEpoch", SourceDateEpochEnv, value)
+		}
+		return timestamp, nil
+	default:
+		timestamp, err := strconv.ParseInt(spec, 10, 64)
+		if err != nil {
+			return 0, Errorf("error.invalidTimestamp", spec)
+		}
+		return timestamp, nil
+	}
+}
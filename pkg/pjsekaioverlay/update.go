@@ -0,0 +1,371 @@
+package pjsekaioverlay
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// リリースに添付されるzipと、そのSHA-256のファイルの名前。
+const (
+	ReleaseAssetName    = "pjsekai-overlay.zip"
+	ReleaseChecksumName = ReleaseAssetName + ".sha256"
+)
+
+// 置き換える前のファイルに付ける接尾辞。実行中のexeは消せないため、次回の起動時に消す。
+const updateOldSuffix = ".update-old"
+
+// セマンティックバージョン。ビルドメタデータは比較に使わないため持たない。
+type SemVer struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease []string
+}
+
+// 「v1.2.3」「1.2.3-beta.1」などを変換する。マイナーとパッチは省略できる。
+func ParseVersion(version string) (SemVer, error) {
+	text := strings.TrimPrefix(strings.TrimSpace(version), "v")
+	text, _, _ = strings.Cut(text, "+")
+	text, prerelease, hasPrerelease := strings.Cut(text, "-")
+
+	parts := strings.Split(text, ".")
+	if len(parts) > 3 {
+		return SemVer{}, NewError(ErrorDecode, nil, "error.invalidVersion", version)
+	}
+	numbers := [3]int{}
+	for i, part := range parts {
+		number, err := strconv.Atoi(part)
+		if err != nil || number < 0 {
+			return SemVer{}, NewError(ErrorDecode, err, "error.invalidVersion", version)
+		}
+		numbers[i] = number
+	}
+
+	semVer := SemVer{Major: numbers[0], Minor: numbers[1], Patch: numbers[2]}
+	if hasPrerelease {
+		if prerelease == "" {
+			return SemVer{}, NewError(ErrorDecode, nil, "error.invalidVersion", version)
+		}
+		semVer.Prerelease = strings.Split(prerelease, ".")
+	}
+	return semVer, nil
+}
+
+func (version SemVer) String() string {
+	text := strconv.Itoa(version.Major) + "." + strconv.Itoa(version.Minor) + "." + strconv.Itoa(version.Patch)
+	if len(version.Prerelease) > 0 {
+		text += "-" + strings.Join(version.Prerelease, ".")
+	}
+	return text
+}
+
+func compareInts(a int, b int) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
+
+// aがbより古ければ負、同じなら0、新しければ正を返す。
+func CompareVersions(a SemVer, b SemVer) int {
+	if result := compareInts(a.Major, b.Major); result != 0 {
+		return result
+	}
+	if result := compareInts(a.Minor, b.Minor); result != 0 {
+		return result
+	}
+	if result := compareInts(a.Patch, b.Patch); result != 0 {
+		return result
+	}
+
+	// プレリリースの無い方が新しい
+	if len(a.Prerelease) == 0 || len(b.Prerelease) == 0 {
+		return compareInts(len(b.Prerelease), len(a.Prerelease))
+	}
+	for i := 0; i < len(a.Prerelease) && i < len(b.Prerelease); i++ {
+		aNumber, aErr := strconv.Atoi(a.Prerelease[i])
+		bNumber, bErr := strconv.Atoi(b.Prerelease[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if result := compareInts(aNumber, bNumber); result != 0 {
+				return result
+			}
+		// 数字だけの識別子の方が古い
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if result := strings.Compare(a.Prerelease[i], b.Prerelease[i]); result != 0 {
+				return result
+			}
+		}
+	}
+	return compareInts(len(a.Prerelease), len(b.Prerelease))
+}
+
+// 名前を引けるかで、インターネットに接続されているかを調べる。
+func IsOnline(host string, timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	addresses, err := net.DefaultResolver.LookupHost(ctx, host)
+	return err == nil && len(addresses) > 0
+}
+
+// 前回アップデートを確認した時刻を保存するファイル。
+func UpdateCheckPath() (string, error) {
+	dir, err := DefaultCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "update-check"), nil
+}
+
+// 前回の確認からinterval以上経っているか。
+func ShouldCheckUpdate(interval time.Duration) bool {
+	path, err := UpdateCheckPath()
+	if err != nil {
+		return false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return os.IsNotExist(err)
+	}
+	lastCheckTime, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		// 壊れている場合は確認し直す
+		return true
+	}
+	return time.Since(time.Unix(lastCheckTime, 0)) > interval
+}
+
+func SaveUpdateCheck(checkedAt time.Time) error {
+	path, err := UpdateCheckPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return NewError(ErrorFilesystem, err, "error.createFile", err)
+	}
+	if err := os.WriteFile(path, []byte(strconv.FormatInt(checkedAt.Unix(), 10)), 0644); err != nil {
+		return NewError(ErrorFilesystem, err, "error.writeFile", err)
+	}
+	return nil
+}
+
+// urlをdestPathにダウンロードし、SHA-256を返す。
+func DownloadFile(name string, url string, destPath string) (string, error) {
+	resp, err := httpGet(name, url)
+	if err != nil {
+		return "", NewError(ErrorNetwork, err, "error.connectCause", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return "", NewError(ErrorNetwork, nil, "error.downloadStatus", resp.StatusCode)
+	}
+
+	file, err := os.Create(destPath)
+	if err != nil {
+		return "", NewError(ErrorFilesystem, err, "error.createFile", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(file, hash), resp.Body); err != nil {
+		return "", NewError(ErrorNetwork, err, "error.connectCause", err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// sha256sumの形式（「ハッシュ  ファイル名」）のファイルから、nameのハッシュを探す。
+func FindChecksum(checksumPath string, name string) (string, error) {
+	file, err := os.Open(checksumPath)
+	if err != nil {
+		return "", NewError(ErrorFilesystem, err, "error.updateChecksumRead", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", NewError(ErrorFilesystem, err, "error.updateChecksumRead", err)
+	}
+	return "", NewError(ErrorDecode, nil, "error.updateChecksumMissing", name)
+}
+
+// ダウンロードしたzipを確かめる。sizeが負の場合はサイズを、expectedChecksumが空の場合はハッシュを確かめない。
+func VerifyUpdate(zipPath string, size int64, checksum string, expectedChecksum string) error {
+	info, err := os.Stat(zipPath)
+	if err != nil {
+		return NewError(ErrorFilesystem, err, "error.updateZip", err)
+	}
+	if size >= 0 && info.Size() != size {
+		return NewError(ErrorDecode, nil, "error.updateSize", info.Size(), size)
+	}
+	if expectedChecksum != "" && !strings.EqualFold(checksum, expectedChecksum) {
+		return NewError(ErrorDecode, nil, "error.updateChecksum")
+	}
+
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return NewError(ErrorDecode, err, "error.updateZip", err)
+	}
+	defer reader.Close()
+
+	hasExecutable := false
+	hasAssets := false
+	for _, file := range reader.File {
+		if file.Name == "pjsekai-overlay.exe" {
+			hasExecutable = true
+		}
+		if strings.HasPrefix(file.Name, "assets/") {
+			hasAssets = true
+		}
+		// 最後まで読むとCRCが確かめられる
+		contents, err := file.Open()
+		if err != nil {
+			return NewError(ErrorDecode, err, "error.updateZip", err)
+		}
+		_, err = io.Copy(io.Discard, contents)
+		contents.Close()
+		if err != nil {
+			return NewError(ErrorDecode, err, "error.updateZip", err)
+		}
+	}
+	if !hasExecutable {
+		return NewError(ErrorDecode, nil, "error.updateMissingFile", "pjsekai-overlay.exe")
+	}
+	if !hasAssets {
+		return NewError(ErrorDecode, nil, "error.updateMissingFile", "assets")
+	}
+	return nil
+}
+
+func extractZip(zipPath string, destDir string) error {
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return NewError(ErrorDecode, err, "error.updateZip", err)
+	}
+	defer reader.Close()
+
+	for _, file := range reader.File {
+		destPath := filepath.Join(destDir, filepath.FromSlash(file.Name))
+		// zipの外に書き込まないようにする
+		if relativePath, err := filepath.Rel(destDir, destPath); err != nil || relativePath == ".." || strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+			return NewError(ErrorDecode, nil, "error.updateZipPath", file.Name)
+		}
+		if file.FileInfo().IsDir() {
+			if err := os.MkdirAll(destPath, 0755); err != nil {
+				return NewError(ErrorFilesystem, err, "error.createFile", err)
+			}
+			continue
+		}
+		if err := extractZipFile(file, destPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractZipFile(file *zip.File, destPath string) error {
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return NewError(ErrorFilesystem, err, "error.createFile", err)
+	}
+	contents, err := file.Open()
+	if err != nil {
+		return NewError(ErrorDecode, err, "error.updateZip", err)
+	}
+	defer contents.Close()
+
+	destFile, err := os.Create(destPath)
+	if err != nil {
+		return NewError(ErrorFilesystem, err, "error.createFile", err)
+	}
+	defer destFile.Close()
+	if _, err := io.Copy(destFile, contents); err != nil {
+		return NewError(ErrorFilesystem, err, "error.writeFile", err)
+	}
+	return nil
+}
+
+// zipの中身でinstallDirのファイルを置き換える。exeはexecutableNameの名前で置き換える。
+// 途中で失敗した場合は、置き換えたファイルを元に戻す。
+func ApplyUpdate(zipPath string, installDir string, executableName string) error {
+	stagingDir, err := os.MkdirTemp(installDir, ".update-")
+	if err != nil {
+		return NewError(ErrorFilesystem, err, "error.createFile", err)
+	}
+	defer os.RemoveAll(stagingDir)
+
+	if err := extractZip(zipPath, stagingDir); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(stagingDir)
+	if err != nil {
+		return NewError(ErrorFilesystem, err, "error.updateReplace", err)
+	}
+
+	// 置き換えたファイルのパス
+	replaced := []string{}
+	rollback := func() {
+		for i := len(replaced) - 1; i >= 0; i-- {
+			os.RemoveAll(replaced[i])
+			os.Rename(replaced[i]+updateOldSuffix, replaced[i])
+		}
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if name == "pjsekai-overlay.exe" {
+			name = executableName
+		}
+		destPath := filepath.Join(installDir, name)
+		oldPath := destPath + updateOldSuffix
+		if err := os.RemoveAll(oldPath); err != nil {
+			rollback()
+			return NewError(ErrorFilesystem, err, "error.updateReplace", err)
+		}
+		if err := os.Rename(destPath, oldPath); err != nil && !os.IsNotExist(err) {
+			rollback()
+			return NewError(ErrorFilesystem, err, "error.updateReplace", err)
+		}
+		replaced = append(replaced, destPath)
+		if err := os.Rename(filepath.Join(stagingDir, entry.Name()), destPath); err != nil {
+			rollback()
+			return NewError(ErrorFilesystem, err, "error.updateReplace", err)
+		}
+	}
+
+	CleanupUpdate(installDir)
+	return nil
+}
+
+// 前回のアップデートで置き換えたファイルを消す。実行中のexeは消せないので無視する。
+func CleanupUpdate(installDir string) {
+	oldPaths, err := filepath.Glob(filepath.Join(installDir, "*"+updateOldSuffix))
+	if err != nil {
+		return
+	}
+	for _, oldPath := range oldPaths {
+		os.RemoveAll(oldPath)
+	}
+}
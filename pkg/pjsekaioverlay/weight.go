@@ -0,0 +1,74 @@
+package pjsekaioverlay
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+
+	"github.com/sevenc-nanashi/pjsekai-overlay/pkg/sonolus"
+)
+
+// ノーツの重みの上書き設定。キーはエンジン名で、「*」は全てのエンジンに適用される。
+//
+//	{
+//	  "*": { "NewTapNote": 1 },
+//	  "pjsekai": { "IgnoredSlideTickNote": 0 }
+//	}
+type WeightConfig map[string]map[string]float64
+
+type UnknownArchetype struct {
+	Name  string
+	Count int
+}
+
+func LoadWeightConfig(path string) (WeightConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, NewError(ErrorFilesystem, err, "error.weightsOpen", err)
+	}
+
+	var config WeightConfig
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		return nil, NewError(ErrorDecode, err, "error.weightsRead", err)
+	}
+
+	return config, nil
+}
+
+func (config WeightConfig) WeightsFor(engine string) map[string]float64 {
+	weights := make(map[string]float64, len(WEIGHT_MAP))
+	for archetype, weight := range WEIGHT_MAP {
+		weights[archetype] = weight
+	}
+	for archetype, weight := range config["*"] {
+		weights[archetype] = weight
+	}
+	if engine != "*" {
+		for archetype, weight := range config[engine] {
+			weights[archetype] = weight
+		}
+	}
+	return weights
+}
+
+func FindUnknownArchetypes(levelData sonolus.LevelData, weights map[string]float64) []UnknownArchetype {
+	counts := map[string]int{}
+	for _, entity := range levelData.Entities {
+		if _, ok := weights[entity.Archetype]; !ok {
+			counts[entity.Archetype]++
+		}
+	}
+
+	unknownArchetypes := make([]UnknownArchetype, 0, len(counts))
+	for name, count := range counts {
+		unknownArchetypes = append(unknownArchetypes, UnknownArchetype{Name: name, Count: count})
+	}
+	sort.Slice(unknownArchetypes, func(i, j int) bool {
+		if unknownArchetypes[i].Count != unknownArchetypes[j].Count {
+			return unknownArchetypes[i].Count > unknownArchetypes[j].Count
+		}
+		return unknownArchetypes[i].Name < unknownArchetypes[j].Name
+	})
+	return unknownArchetypes
+}
@@ -23,7 +23,8 @@ type BackgroundInfo struct {
 }
 
 type EngineInfo struct {
-	Version int `json:"version"`
+	Name    string `json:"name"`
+	Version int    `json:"version"`
 }
 
 type SRL struct {
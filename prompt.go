@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+	"github.com/sevenc-nanashi/pjsekai-overlay/pkg/pjsekaioverlay"
+	"github.com/srinathh/gokilo/rawmode"
+)
+
+func stdinIsTerminal() bool {
+	fd := os.Stdin.Fd()
+	return isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
+}
+
+func stdoutIsTerminal() bool {
+	fd := os.Stdout.Fd()
+	return isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
+}
+
+// コマンドラインで指定されたオプションの名前
+func specifiedFlags(flagSet *flag.FlagSet) map[string]bool {
+	specified := map[string]bool{}
+	flagSet.Visit(func(f *flag.Flag) {
+		specified[f.Name] = true
+	})
+	return specified
+}
+
+// 対話的な入力に関するオプション。
+type inputFlags struct {
+	yes       bool
+	noInput   bool
+	specified map[string]bool
+}
+
+func (flags *inputFlags) register(flagSet *flag.FlagSet) {
+	flagSet.BoolVar(&flags.yes, "yes", false, t("flag.yes"))
+	flagSet.BoolVar(&flags.noInput, "no-input", false, t("flag.noInput"))
+}
+
+// flagSet.Parseの後に呼ぶ。
+func (flags *inputFlags) parsed(flagSet *flag.FlagSet) {
+	flags.specified = specifiedFlags(flagSet)
+}
+
+// 標準入力から読み込めるか。スクリプトなどから起動された場合や--jsonの場合はfalse。
+func (flags *inputFlags) canAsk() bool {
+	return !flags.noInput && report.interactive() && stdinIsTerminal()
+}
+
+// 既定の値があるオプションを尋ねるか。コマンドラインで指定されている場合は尋ねない。
+func (flags *inputFlags) shouldAsk(name string) bool {
+	return !flags.yes && !flags.specified[name] && flags.canAsk()
+}
+
+// メッセージの言語を決めた後に作るため関数にしている。
+func errNoInput() error {
+	return pjsekaioverlay.Errorf("error.noInput")
+}
+
+func promptString(message string) string {
+	fmt.Printf("%s\n> ", message)
+	var value string
+	fmt.Scanln(&value)
+	fmt.Printf("\033[A\033[2K\r> %s\n", color.GreenString(value))
+	return value
+}
+
+func promptInt(message string) (int, error) {
+	return strconv.Atoi(strings.TrimSpace(promptString(message)))
+}
+
+// Y/nで尋ねる。何も入力されなかった場合はdefaultValue。
+func promptYesNo(message string, defaultValue bool) bool {
+	if defaultValue {
+		fmt.Printf("%s (Y/n)\n> ", message)
+	} else {
+		fmt.Printf("%s (y/N)\n> ", message)
+	}
+	before, _ := rawmode.Enable()
+	answerByte, _ := bufio.NewReader(os.Stdin).ReadByte()
+	answer := string(answerByte)
+	rawmode.Restore(before)
+	fmt.Printf("\n\033[A\033[2K\r> %s\n", color.GreenString(answer))
+	switch answer {
+	case "Y", "y":
+		return true
+	case "", "\r", "\n":
+		return defaultValue
+	default:
+		return false
+	}
+}
+
+// ダブルクリックで起動された場合など、ウィンドウがすぐに閉じないように待つ。
+func waitForKey() {
+	if !stdinIsTerminal() {
+		return
+	}
+	fmt.Print(color.CyanString(t("common.pressKey")))
+
+	before, _ := rawmode.Enable()
+	bufio.NewReader(os.Stdin).ReadByte()
+	rawmode.Restore(before)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/sevenc-nanashi/pjsekai-overlay/pkg/pjsekaioverlay"
+	"github.com/sevenc-nanashi/pjsekai-overlay/pkg/sonolus"
+)
+
+// 出力先ディレクトリのマニフェストに書かれたオプションで、pedファイル、exoファイル、タイムラインとグラフを生成し直す。
+func regenMain(args []string) int {
+	flagSet := flag.NewFlagSet("regen", flag.ExitOnError)
+
+	var refresh bool
+	flagSet.BoolVar(&refresh, "refresh", false, t("flag.refresh"))
+
+	var scoreFlags scoreFlags
+	scoreFlags.register(flagSet)
+
+	var pedFlags pedFlags
+	pedFlags.register(flagSet)
+
+	var extraOutputFlags extraOutputFlags
+	extraOutputFlags.register(flagSet)
+
+	var exoFlags exoFlags
+	exoFlags.register(flagSet)
+
+	var configFlags configFlags
+	configFlags.register(flagSet)
+
+	var reportFlags reportFlags
+	reportFlags.register(flagSet)
+
+	flagSet.Usage = func() {
+		fmt.Println(t("usage.regen"))
+		flagSet.PrintDefaults()
+	}
+	flagSet.Parse(args)
+	reportFlags.apply()
+	if flagSet.NArg() != 1 {
+		flagSet.Usage()
+		return 2
+	}
+	// 設定ファイルの値より前に調べる
+	specified := specifiedFlags(flagSet)
+
+	outDir, err := filepath.Abs(flagSet.Arg(0))
+	if err != nil {
+		return fail(stageOptions, err)
+	}
+	manifest, err := pjsekaioverlay.ReadManifest(outDir)
+	if err != nil {
+		return fail(stageManifest, err)
+	}
+
+	// コマンドライン、マニフェスト、設定ファイルの順に優先する
+	if err := applyManifestOptions(flagSet, manifest, specified); err != nil {
+		return fail(stageOptions, err)
+	}
+	if err := configFlags.apply(flagSet); err != nil {
+		return fail(stageOptions, err)
+	}
+	reportFlags.apply()
+	report.info(t("common.chartId", color.GreenString(manifest.ChartId)))
+	if manifest.Version != pjsekaioverlay.Version {
+		report.info(t("regen.version", strings.TrimPrefix(manifest.Version, "v"), strings.TrimPrefix(pjsekaioverlay.Version, "v")))
+	}
+
+	skills, err := scoreFlags.skills()
+	if err != nil {
+		return fail(stageOptions, err)
+	}
+	judgeTable, err := scoreFlags.judgeTable()
+	if err != nil {
+		return fail(stageOptions, err)
+	}
+	pedOptions, err := pedFlags.pedOptions(skills, judgeTable.Life)
+	if err != nil {
+		return fail(stageOptions, err)
+	}
+	exoOptions, err := exoFlags.exoOptions()
+	if err != nil {
+		return fail(stageOptions, err)
+	}
+
+	cache := openCache()
+	var chartSource pjsekaioverlay.Source
+	var chart sonolus.LevelInfo
+	if refresh {
+		chartSource, chart, err = fetchChart(cache, manifest.ChartId)
+		if err != nil {
+			return exitCode(err)
+		}
+		if err := downloadResources(chartSource, chart, outDir); err != nil {
+			return exitCode(err)
+		}
+	} else {
+		chartSource, chart, err = loadChart(cache, manifest.ChartId)
+		if err != nil {
+			return exitCode(err)
+		}
+	}
+	// 譜面データはハッシュ毎にキャッシュされるので、譜面が更新されていれば取得し直される
+	levelData, err := loadLevelData(cache, chartSource, chart)
+	if err != nil {
+		return exitCode(err)
+	}
+
+	scoreData, err := calculateScore(chart, levelData, &scoreFlags, skills, judgeTable)
+	if err != nil {
+		return exitCode(err)
+	}
+	assets, pedAssets, err := resolveAssets(outDir, pedFlags.portable)
+	if err != nil {
+		return exitCode(err)
+	}
+	pedOptions.Assets = pedAssets
+	pedOptions.LevelInfo = sonolus.LevelInfo{Rating: chart.Rating}
+
+	if err := writePed(scoreData, outDir, pedOptions); err != nil {
+		return exitCode(err)
+	}
+	if err := writeExtraOutputs(scoreData, chart, outDir, pedOptions, extraOutputFlags); err != nil {
+		return exitCode(err)
+	}
+	if err := writeExo(chartSource, chart, assets, outDir, exoOptions); err != nil {
+		return exitCode(err)
+	}
+	if err := writeManifest(manifest.ChartId, chartSource, chart, manifestOptions(flagSet, manifest.Options), outDir); err != nil {
+		return exitCode(err)
+	}
+
+	report.info(color.GreenString(t("regen.done")))
+	return 0
+}
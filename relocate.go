@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/sevenc-nanashi/pjsekai-overlay/pkg/pjsekaioverlay"
+)
+
+func relocateMain(args []string) int {
+	flagSet := flag.NewFlagSet("relocate", flag.ExitOnError)
+
+	var assets string
+	flagSet.StringVar(&assets, "assets", "", t("flag.assets"))
+
+	var reportFlags reportFlags
+	reportFlags.register(flagSet)
+
+	flagSet.Usage = func() {
+		fmt.Println(t("usage.relocate"))
+		flagSet.PrintDefaults()
+	}
+	flagSet.Parse(args)
+	reportFlags.apply()
+	if flagSet.NArg() != 1 {
+		flagSet.Usage()
+		return 2
+	}
+
+	destDir, err := filepath.Abs(flagSet.Arg(0))
+	if err != nil {
+		return fail(stageOptions, err)
+	}
+	assets, err = findAssets(destDir, assets)
+	if err != nil {
+		return fail(stageAssets, err)
+	}
+	report.info(t("common.outDir", color.CyanString(destDir)))
+	report.info(t("common.assets", color.CyanString(assets)))
+
+	pedPath := filepath.Join(destDir, "data.ped")
+	pedFile, err := pjsekaioverlay.ParsePedFile(pedPath)
+	if err != nil {
+		return fail(stageRelocate, err)
+	}
+
+	// exoの書き換えには元のアセットのパスが必要なので、pedファイルは後で書き換える
+	report.stageStarted(stageRelocate, t("progress.relocateExo"))
+	exoPath := filepath.Join(destDir, "main.exo")
+	err = pjsekaioverlay.RelocateExoFile(exoPath, destDir, pedFile.Assets, assets)
+	if err != nil {
+		return fail(stageRelocate, err)
+	}
+
+	report.stageFinished(stageRelocate)
+	report.output("exo", exoPath)
+
+	report.stageStarted(stageRelocate, t("progress.relocatePed"))
+	err = pjsekaioverlay.RelocatePedFile(pedPath, pjsekaioverlay.PedAssetsPath(assets, destDir))
+	if err != nil {
+		return fail(stageRelocate, err)
+	}
+
+	report.stageFinished(stageRelocate)
+	report.output("ped", pedPath)
+
+	return 0
+}
@@ -0,0 +1,648 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/sevenc-nanashi/pjsekai-overlay/pkg/pjsekaioverlay"
+	"github.com/sevenc-nanashi/pjsekai-overlay/pkg/sonolus"
+)
+
+// 処理の段階。--jsonの出力と終了コードに使う。
+const (
+	stageOptions       = "options"
+	stageFetchChart    = "fetchChart"
+	stageDownload      = "download"
+	stageLevelData     = "levelData"
+	stageJudgements    = "judgements"
+	stageScore         = "score"
+	stageAssets        = "assets"
+	stagePed           = "ped"
+	stageExo           = "exo"
+	stageTimeline      = "timeline"
+	stageGraph         = "graph"
+	stageRelocate      = "relocate"
+	stageInstallObject = "installObject"
+	stageUpdate        = "update"
+	stageManifest      = "manifest"
+	stageCache         = "cache"
+)
+
+// 失敗した段階毎の終了コード。
+var STAGE_EXIT_CODES = map[string]int{
+	stageOptions:       2,
+	stageFetchChart:    3,
+	stageDownload:      4,
+	stageLevelData:     5,
+	stageJudgements:    6,
+	stageScore:         6,
+	stageAssets:        7,
+	stagePed:           8,
+	stageExo:           9,
+	stageTimeline:      10,
+	stageGraph:         11,
+	stageRelocate:      12,
+	stageInstallObject: 13,
+	stageUpdate:        14,
+	stageManifest:      15,
+	stageCache:         16,
+}
+
+// エラーの種類毎の終了コード。段階毎の終了コードより優先する。
+var ERROR_KIND_EXIT_CODES = map[pjsekaioverlay.ErrorKind]int{
+	pjsekaioverlay.ErrorUnknownSource:     20,
+	pjsekaioverlay.ErrorChartNotFound:     21,
+	pjsekaioverlay.ErrorNetwork:           22,
+	pjsekaioverlay.ErrorUnsupportedEngine: 23,
+	pjsekaioverlay.ErrorDecode:            24,
+	pjsekaioverlay.ErrorFilesystem:        25,
+	pjsekaioverlay.ErrorEncoding:          26,
+}
+
+// 進捗や結果の出力先。通常はコンソールに色付きで、--jsonではJSON Linesで出力する。
+type reporter interface {
+	// 対話的な入力を求めてよいか
+	interactive() bool
+	info(message string)
+	stageStarted(stage string, message string)
+	stageFinished(stage string)
+	stageFailed(stage string, err error)
+	warning(code string, message string, details []string)
+	chart(chartId string, chartSource pjsekaioverlay.Source, chart sonolus.LevelInfo)
+	score(scoreData pjsekaioverlay.ScoreResult, rank string)
+	output(kind string, path string)
+	download(progress pjsekaioverlay.DownloadProgress)
+	downloaded(progress pjsekaioverlay.DownloadProgress)
+	check(status string, name string, message string)
+	// formatは--formatの値。JSONで出力する場合は無視する
+	stats(stats pjsekaioverlay.ChartStats, format string) error
+	pedSummary(summary pjsekaioverlay.PedSummary)
+	pedProblems(problems []pjsekaioverlay.PedProblem)
+	pedDifferences(differences []pjsekaioverlay.PedDifference)
+	cacheEntries(entries []pjsekaioverlay.CacheEntry)
+	cacheCleared(dir string)
+	finished(exitCode int)
+}
+
+var report reporter = &consoleReporter{}
+
+// pjsekaioverlayのダウンロードの進捗を、その時のreportに渡す。
+type reportProgress struct{}
+
+func (reportProgress) Downloading(progress pjsekaioverlay.DownloadProgress) {
+	report.download(progress)
+}
+
+func (reportProgress) Downloaded(progress pjsekaioverlay.DownloadProgress) {
+	report.downloaded(progress)
+}
+
+// 段階と、--jsonで出力するエラーコードを持つエラー。
+type stageError struct {
+	stage string
+	code  string
+	err   error
+}
+
+func (err *stageError) Error() string {
+	return err.err.Error()
+}
+
+func (err *stageError) Unwrap() error {
+	return err.err
+}
+
+// 特定のエラーコードを付ける。
+func withCode(code string, err error) error {
+	return &stageError{code: code, err: err}
+}
+
+func errorCode(stage string, err error) string {
+	var coded *stageError
+	if errors.As(err, &coded) && coded.code != "" {
+		return coded.code
+	}
+	if kind := pjsekaioverlay.KindOf(err); kind != "" {
+		return string(kind)
+	}
+	return stage + "Failed"
+}
+
+// 失敗を出力し、段階を記録したエラーを返す。
+func failStage(stage string, err error) error {
+	report.stageFailed(stage, err)
+	return &stageError{stage: stage, code: errorCode(stage, err), err: err}
+}
+
+// 失敗を出力し、終了コードを返す。
+func fail(stage string, err error) int {
+	return exitCode(failStage(stage, err))
+}
+
+func exitCode(err error) int {
+	if code, ok := ERROR_KIND_EXIT_CODES[pjsekaioverlay.KindOf(err)]; ok {
+		return code
+	}
+	var failed *stageError
+	if errors.As(err, &failed) && failed.stage != "" {
+		return STAGE_EXIT_CODES[failed.stage]
+	}
+	return 1
+}
+
+// --jsonのオプション。
+type reportFlags struct {
+	json bool
+}
+
+func (flags *reportFlags) register(flagSet *flag.FlagSet) {
+	flagSet.BoolVar(&flags.json, "json", false, t("flag.json"))
+}
+
+// flagSet.Parseの後と、設定ファイルを適用した後に呼ぶ。
+func (flags *reportFlags) apply() {
+	if flags.json {
+		report = &jsonReporter{encoder: json.NewEncoder(os.Stdout)}
+	}
+}
+
+type consoleReporter struct {
+	// 進捗などの出力先。nilの場合は標準出力
+	writer io.Writer
+	// 「〜中... 」を表示してから成功か失敗を表示するまでの間か
+	inStage bool
+	// 「〜中... 」の部分。進捗バーを消す時に表示し直す
+	stageMessage string
+}
+
+func (reporter *consoleReporter) out() io.Writer {
+	if reporter.writer == nil {
+		return os.Stdout
+	}
+	return reporter.writer
+}
+
+func (reporter *consoleReporter) interactive() bool {
+	return true
+}
+
+func (reporter *consoleReporter) info(message string) {
+	fmt.Fprintln(reporter.out(), message)
+}
+
+func (reporter *consoleReporter) stageStarted(stage string, message string) {
+	reporter.inStage = true
+	reporter.stageMessage = message
+	fmt.Fprint(reporter.out(), message)
+}
+
+func (reporter *consoleReporter) stageFinished(stage string) {
+	reporter.inStage = false
+	fmt.Fprintln(reporter.out(), color.GreenString(t("common.success")))
+}
+
+func (reporter *consoleReporter) stageFailed(stage string, err error) {
+	if reporter.inStage {
+		reporter.inStage = false
+		fmt.Fprintln(reporter.out(), color.RedString(t("common.failure", err.Error())))
+	} else {
+		fmt.Fprintln(reporter.out(), color.RedString(err.Error()))
+	}
+}
+
+func (reporter *consoleReporter) warning(code string, message string, details []string) {
+	fmt.Fprintln(reporter.out(), color.YellowString(message))
+	for _, detail := range details {
+		fmt.Fprintf(reporter.out(), "  %s\n", detail)
+	}
+}
+
+func (reporter *consoleReporter) chart(chartId string, chartSource pjsekaioverlay.Source, chart sonolus.LevelInfo) {
+	fmt.Fprintf(reporter.out(), "  %s / %s - %s (Lv. %s)\n",
+		color.CyanString(chart.Title),
+		color.CyanString(chart.Artists),
+		color.CyanString(chart.Author),
+		color.MagentaString(strconv.Itoa(chart.Rating)),
+	)
+}
+
+func (reporter *consoleReporter) score(scoreData pjsekaioverlay.ScoreResult, rank string) {
+	PrintScoreBreakdown(scoreData)
+	if rank != "" {
+		fmt.Fprintln(reporter.out(), t("score.rank", color.MagentaString(rank)))
+	}
+}
+
+func (reporter *consoleReporter) output(kind string, path string) {
+}
+
+func (reporter *consoleReporter) download(progress pjsekaioverlay.DownloadProgress) {
+	if !stdoutIsTerminal() {
+		return
+	}
+	fmt.Fprint(reporter.out(), "\r\x1b[2K"+reporter.stageMessage+formatDownloadProgress(progress))
+}
+
+func (reporter *consoleReporter) downloaded(progress pjsekaioverlay.DownloadProgress) {
+	if !stdoutIsTerminal() {
+		return
+	}
+	fmt.Fprint(reporter.out(), "\r\x1b[2K"+reporter.stageMessage)
+}
+
+// 進捗バーの幅
+const progressBarWidth = 24
+
+// 「[=====>      ]  40%  1.2 MB / 3.0 MB  850.0 KB/s  残り2s」のような進捗バー。
+func formatDownloadProgress(progress pjsekaioverlay.DownloadProgress) string {
+	parts := []string{}
+	if progress.Total > 0 {
+		ratio := float64(progress.Bytes) / float64(progress.Total)
+		if ratio > 1 {
+			ratio = 1
+		}
+		filled := int(ratio * progressBarWidth)
+		bar := strings.Repeat("=", filled)
+		if filled < progressBarWidth {
+			bar += ">" + strings.Repeat(" ", progressBarWidth-filled-1)
+		}
+		parts = append(parts,
+			"["+color.CyanString(bar)+"]",
+			fmt.Sprintf("%3d%%", int(ratio*100)),
+			formatBytes(progress.Bytes)+" / "+formatBytes(progress.Total),
+		)
+	} else {
+		parts = append(parts, formatBytes(progress.Bytes))
+	}
+	parts = append(parts, formatBytes(int64(progress.Rate()))+"/s")
+	if eta, ok := progress.Eta(); ok {
+		parts = append(parts, t("progress.eta", eta.Round(time.Second)))
+	}
+	return strings.Join(parts, "  ")
+}
+
+func formatBytes(bytes int64) string {
+	units := []string{"B", "KB", "MB", "GB"}
+	value := float64(bytes)
+	unit := 0
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+	if unit == 0 {
+		return fmt.Sprintf("%d %s", bytes, units[unit])
+	}
+	return fmt.Sprintf("%.1f %s", value, units[unit])
+}
+
+func (reporter *consoleReporter) check(status string, name string, message string) {
+	statusText := map[string]string{
+		"ok":   color.GreenString("OK"),
+		"warn": color.YellowString("!!"),
+		"fail": color.RedString("NG"),
+	}[status]
+	fmt.Fprintln(reporter.out(), t("doctor.line", statusText, name, message))
+}
+
+func (reporter *consoleReporter) stats(stats pjsekaioverlay.ChartStats, format string) error {
+	switch format {
+	case "json":
+		return pjsekaioverlay.WriteStatsJSON(os.Stdout, stats)
+	case "csv":
+		return pjsekaioverlay.WriteStatsCSV(os.Stdout, stats)
+	default:
+		return pjsekaioverlay.WriteStatsTable(os.Stdout, stats)
+	}
+}
+
+func (reporter *consoleReporter) pedSummary(summary pjsekaioverlay.PedSummary) {
+	out := reporter.out()
+	fmt.Fprint(out, t("inspect.formatVersion", summary.FormatVersion))
+	fmt.Fprint(out, t("inspect.version", summary.Version))
+	fmt.Fprint(out, t("inspect.timestamp", time.Unix(summary.Timestamp, 0).Format("2006-01-02 15:04:05")))
+	fmt.Fprint(out, t("inspect.assets", summary.Assets))
+	fmt.Fprint(out, t("inspect.ap", summary.Ap))
+	fmt.Fprint(out, t("inspect.scores", summary.Scores))
+	fmt.Fprint(out, t("inspect.duration", summary.Duration))
+	fmt.Fprint(out, t("inspect.finalScore", color.MagentaString("%d", summary.FinalScore), summary.FinalRank))
+	fmt.Fprint(out, t("inspect.maxCombo", summary.MaxCombo))
+	fmt.Fprint(out, t("inspect.finalLife", summary.FinalLife))
+	if summary.FrameTable != nil {
+		fmt.Fprint(out, t("inspect.frameTable", summary.FrameTable.Fps, len(summary.FrameTable.Runs)))
+	} else {
+		fmt.Fprint(out, t("inspect.frameTableNone"))
+	}
+
+	eventTypes := make([]string, 0, len(summary.Events))
+	for eventType := range summary.Events {
+		eventTypes = append(eventTypes, eventType)
+	}
+	sort.Strings(eventTypes)
+	fmt.Fprintln(out, t("inspect.events"))
+	for _, eventType := range eventTypes {
+		fmt.Fprintf(out, "  %s: %d\n", eventType, summary.Events[eventType])
+	}
+	fmt.Fprintln(out, t("inspect.firstEvents"))
+	for _, pedEvent := range summary.FirstEvents {
+		fmt.Fprintf(out, "  %s\n", pjsekaioverlay.FormatPedEvent(pedEvent))
+	}
+	fmt.Fprintln(out, t("inspect.lastEvents"))
+	for _, pedEvent := range summary.LastEvents {
+		fmt.Fprintf(out, "  %s\n", pjsekaioverlay.FormatPedEvent(pedEvent))
+	}
+}
+
+// 「s#12」のような、レコードの種類と番号。ファイル全体の場合は種類だけ
+func formatPedRecord(record string, index int) string {
+	if index > 0 {
+		return fmt.Sprintf("%s#%d", record, index)
+	}
+	return record
+}
+
+func (reporter *consoleReporter) pedProblems(problems []pjsekaioverlay.PedProblem) {
+	if len(problems) == 0 {
+		fmt.Fprintln(reporter.out(), color.GreenString(t("validate.ok")))
+		return
+	}
+	for _, problem := range problems {
+		fmt.Fprintf(reporter.out(), "%s %s\n", color.YellowString(formatPedRecord(problem.Record, problem.Index)), problem.Message)
+	}
+	fmt.Fprintln(reporter.out(), color.RedString(t("validate.problems", len(problems))))
+}
+
+func (reporter *consoleReporter) pedDifferences(differences []pjsekaioverlay.PedDifference) {
+	if len(differences) == 0 {
+		fmt.Fprintln(reporter.out(), color.GreenString(t("diff.same")))
+		return
+	}
+	for _, difference := range differences {
+		fmt.Fprintln(reporter.out(), color.CyanString(formatPedRecord(difference.Record, difference.Index)))
+		if difference.Left != "" {
+			fmt.Fprintln(reporter.out(), color.RedString("- %s", difference.Left))
+		}
+		if difference.Right != "" {
+			fmt.Fprintln(reporter.out(), color.GreenString("+ %s", difference.Right))
+		}
+	}
+	fmt.Fprintln(reporter.out(), t("diff.count", len(differences)))
+}
+
+func (reporter *consoleReporter) cacheEntries(entries []pjsekaioverlay.CacheEntry) {
+	tableWriter := tabwriter.NewWriter(reporter.out(), 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tableWriter, t("cache.header"))
+	for _, entry := range entries {
+		fmt.Fprintf(tableWriter, "%s\t%s\t%d\t%s\n", entry.Kind, entry.Name, entry.Size, entry.ModTime.Format("2006-01-02 15:04:05"))
+	}
+	tableWriter.Flush()
+}
+
+func (reporter *consoleReporter) cacheCleared(dir string) {
+	fmt.Fprintln(reporter.out(), color.GreenString(t("cache.cleared")))
+}
+
+func (reporter *consoleReporter) finished(exitCode int) {
+}
+
+// 1行に1つのイベントを出力する。
+type jsonReporter struct {
+	encoder *json.Encoder
+}
+
+type jsonChart struct {
+	Id      string `json:"id"`
+	Source  string `json:"source"`
+	Title   string `json:"title"`
+	Artists string `json:"artists"`
+	Author  string `json:"author"`
+	Rating  int    `json:"rating"`
+	Engine  string `json:"engine"`
+}
+
+type jsonScore struct {
+	pjsekaioverlay.ScoreBreakdown
+	FinalScore int    `json:"finalScore"`
+	Rank       string `json:"rank,omitempty"`
+}
+
+type jsonDownload struct {
+	Name           string   `json:"name"`
+	Bytes          int64    `json:"bytes"`
+	Total          *int64   `json:"total,omitempty"`
+	BytesPerSecond float64  `json:"bytesPerSecond"`
+	EtaSeconds     *float64 `json:"etaSeconds,omitempty"`
+}
+
+type jsonPedSummary struct {
+	FormatVersion int            `json:"formatVersion"`
+	Version       string         `json:"version"`
+	Timestamp     int64          `json:"timestamp"`
+	Assets        string         `json:"assets"`
+	Ap            bool           `json:"ap"`
+	Scores        int            `json:"scores"`
+	Events        map[string]int `json:"events"`
+	// フレーム毎の表が無い場合は省略
+	FrameTableFps  *float64 `json:"frameTableFps,omitempty"`
+	FrameTableRuns int      `json:"frameTableRuns"`
+	Duration       float64  `json:"duration"`
+	FinalScore     int      `json:"finalScore"`
+	FinalRank      string   `json:"finalRank"`
+	MaxCombo       int      `json:"maxCombo"`
+	FinalLife      int      `json:"finalLife"`
+	// pedファイルのeレコードと同じ形式
+	FirstEvents []string `json:"firstEvents"`
+	LastEvents  []string `json:"lastEvents"`
+}
+
+type jsonCacheEntry struct {
+	Kind    string    `json:"kind"`
+	Name    string    `json:"name"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+}
+
+type jsonEvent struct {
+	Event    string        `json:"event"`
+	Stage    string        `json:"stage,omitempty"`
+	Code     string        `json:"code,omitempty"`
+	Status   string        `json:"status,omitempty"`
+	Name     string        `json:"name,omitempty"`
+	Message  string        `json:"message,omitempty"`
+	Details  []string      `json:"details,omitempty"`
+	Kind     string        `json:"kind,omitempty"`
+	Path     string        `json:"path,omitempty"`
+	Chart    *jsonChart    `json:"chart,omitempty"`
+	Score    *jsonScore    `json:"score,omitempty"`
+	Download *jsonDownload `json:"download,omitempty"`
+	ExitCode *int          `json:"exitCode,omitempty"`
+
+	Stats *pjsekaioverlay.ChartStats `json:"stats,omitempty"`
+	Ped   *jsonPedSummary            `json:"ped,omitempty"`
+	// problem / differenceのレコードの種類と番号。ファイル全体の場合は番号を省略
+	Record     string          `json:"record,omitempty"`
+	Index      int             `json:"index,omitempty"`
+	Left       string          `json:"left,omitempty"`
+	Right      string          `json:"right,omitempty"`
+	CacheEntry *jsonCacheEntry `json:"cacheEntry,omitempty"`
+}
+
+func (reporter *jsonReporter) emit(event jsonEvent) {
+	reporter.encoder.Encode(event)
+}
+
+func (reporter *jsonReporter) interactive() bool {
+	return false
+}
+
+func (reporter *jsonReporter) info(message string) {
+}
+
+func (reporter *jsonReporter) stageStarted(stage string, message string) {
+	reporter.emit(jsonEvent{Event: "stageStarted", Stage: stage})
+}
+
+func (reporter *jsonReporter) stageFinished(stage string) {
+	reporter.emit(jsonEvent{Event: "stageFinished", Stage: stage})
+}
+
+func (reporter *jsonReporter) stageFailed(stage string, err error) {
+	reporter.emit(jsonEvent{Event: "error", Stage: stage, Code: errorCode(stage, err), Message: err.Error()})
+}
+
+func (reporter *jsonReporter) warning(code string, message string, details []string) {
+	reporter.emit(jsonEvent{Event: "warning", Code: code, Message: strings.TrimSpace(message), Details: details})
+}
+
+func (reporter *jsonReporter) chart(chartId string, chartSource pjsekaioverlay.Source, chart sonolus.LevelInfo) {
+	reporter.emit(jsonEvent{Event: "chart", Chart: &jsonChart{
+		Id:      chartId,
+		Source:  chartSource.Id,
+		Title:   chart.Title,
+		Artists: chart.Artists,
+		Author:  chart.Author,
+		Rating:  chart.Rating,
+		Engine:  chart.Engine.Name,
+	}})
+}
+
+func (reporter *jsonReporter) score(scoreData pjsekaioverlay.ScoreResult, rank string) {
+	finalScore := 0
+	if len(scoreData.Frames) > 0 {
+		finalScore = scoreData.Frames[len(scoreData.Frames)-1].Score
+	}
+	reporter.emit(jsonEvent{Event: "score", Score: &jsonScore{
+		ScoreBreakdown: scoreData.Breakdown,
+		FinalScore:     finalScore,
+		Rank:           rank,
+	}})
+}
+
+func (reporter *jsonReporter) output(kind string, path string) {
+	reporter.emit(jsonEvent{Event: "output", Kind: kind, Path: path})
+}
+
+func newJsonDownload(progress pjsekaioverlay.DownloadProgress) *jsonDownload {
+	download := &jsonDownload{
+		Name:           progress.Name,
+		Bytes:          progress.Bytes,
+		BytesPerSecond: progress.Rate(),
+	}
+	if progress.Total >= 0 {
+		download.Total = &progress.Total
+	}
+	if eta, ok := progress.Eta(); ok {
+		etaSeconds := eta.Seconds()
+		download.EtaSeconds = &etaSeconds
+	}
+	return download
+}
+
+func (reporter *jsonReporter) download(progress pjsekaioverlay.DownloadProgress) {
+	reporter.emit(jsonEvent{Event: "download", Download: newJsonDownload(progress)})
+}
+
+func (reporter *jsonReporter) downloaded(progress pjsekaioverlay.DownloadProgress) {
+	reporter.emit(jsonEvent{Event: "downloaded", Download: newJsonDownload(progress)})
+}
+
+func (reporter *jsonReporter) check(status string, name string, message string) {
+	reporter.emit(jsonEvent{Event: "check", Status: status, Name: name, Message: message})
+}
+
+func (reporter *jsonReporter) stats(stats pjsekaioverlay.ChartStats, format string) error {
+	reporter.emit(jsonEvent{Event: "stats", Stats: &stats})
+	return nil
+}
+
+func formatPedEvents(pedEvents []pjsekaioverlay.PedEvent) []string {
+	formatted := make([]string, len(pedEvents))
+	for i, pedEvent := range pedEvents {
+		formatted[i] = pjsekaioverlay.FormatPedEvent(pedEvent)
+	}
+	return formatted
+}
+
+func (reporter *jsonReporter) pedSummary(summary pjsekaioverlay.PedSummary) {
+	ped := &jsonPedSummary{
+		FormatVersion: summary.FormatVersion,
+		Version:       summary.Version,
+		Timestamp:     summary.Timestamp,
+		Assets:        summary.Assets,
+		Ap:            summary.Ap,
+		Scores:        summary.Scores,
+		Events:        summary.Events,
+		Duration:      summary.Duration,
+		FinalScore:    summary.FinalScore,
+		FinalRank:     summary.FinalRank,
+		MaxCombo:      summary.MaxCombo,
+		FinalLife:     summary.FinalLife,
+		FirstEvents:   formatPedEvents(summary.FirstEvents),
+		LastEvents:    formatPedEvents(summary.LastEvents),
+	}
+	if summary.FrameTable != nil {
+		ped.FrameTableFps = &summary.FrameTable.Fps
+		ped.FrameTableRuns = len(summary.FrameTable.Runs)
+	}
+	reporter.emit(jsonEvent{Event: "ped", Ped: ped})
+}
+
+func (reporter *jsonReporter) pedProblems(problems []pjsekaioverlay.PedProblem) {
+	for _, problem := range problems {
+		reporter.emit(jsonEvent{Event: "problem", Record: problem.Record, Index: problem.Index, Message: problem.Message})
+	}
+}
+
+func (reporter *jsonReporter) pedDifferences(differences []pjsekaioverlay.PedDifference) {
+	for _, difference := range differences {
+		reporter.emit(jsonEvent{Event: "difference", Record: difference.Record, Index: difference.Index, Left: difference.Left, Right: difference.Right})
+	}
+}
+
+func (reporter *jsonReporter) cacheEntries(entries []pjsekaioverlay.CacheEntry) {
+	for _, entry := range entries {
+		reporter.emit(jsonEvent{Event: "cacheEntry", CacheEntry: &jsonCacheEntry{
+			Kind:    entry.Kind,
+			Name:    entry.Name,
+			Size:    entry.Size,
+			ModTime: entry.ModTime,
+		}})
+	}
+}
+
+func (reporter *jsonReporter) cacheCleared(dir string) {
+	reporter.emit(jsonEvent{Event: "cacheCleared", Path: dir})
+}
+
+func (reporter *jsonReporter) finished(exitCode int) {
+	reporter.emit(jsonEvent{Event: "finished", ExitCode: &exitCode})
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/sevenc-nanashi/pjsekai-overlay/pkg/pjsekaioverlay"
+	"github.com/sevenc-nanashi/pjsekai-overlay/pkg/sonolus"
+)
+
+// スコアを計算して内訳を表示する。pedファイルは生成しない。
+func scoreMain(args []string) int {
+	flagSet := flag.NewFlagSet("score", flag.ExitOnError)
+
+	var scoreFlags scoreFlags
+	scoreFlags.register(flagSet)
+
+	var rankTableName string
+	flagSet.StringVar(&rankTableName, "rank-table", "solo", t("flag.rankTable"))
+
+	var outputPath string
+	flagSet.StringVar(&outputPath, "output", "", t("flag.output"))
+
+	var timelineFps float64
+	flagSet.Float64Var(&timelineFps, "timeline-fps", 60, t("flag.timelineFps"))
+
+	var configFlags configFlags
+	configFlags.register(flagSet)
+
+	var reportFlags reportFlags
+	reportFlags.register(flagSet)
+
+	flagSet.Usage = func() {
+		fmt.Println(t("usage.score"))
+		flagSet.PrintDefaults()
+	}
+	flagSet.Parse(args)
+	reportFlags.apply()
+	if err := configFlags.apply(flagSet); err != nil {
+		return fail(stageOptions, err)
+	}
+	reportFlags.apply()
+
+	chartId := flagSet.Arg(0)
+	if chartId == "" {
+		flagSet.Usage()
+		return 2
+	}
+	skills, err := scoreFlags.skills()
+	if err != nil {
+		return fail(stageOptions, err)
+	}
+	judgeTable, err := scoreFlags.judgeTable()
+	if err != nil {
+		return fail(stageOptions, err)
+	}
+	rankTable, err := pjsekaioverlay.GetRankTable(rankTableName)
+	if err != nil {
+		return fail(stageOptions, err)
+	}
+
+	cache := openCache()
+	chartSource, chart, err := fetchChart(cache, chartId)
+	if err != nil {
+		return exitCode(err)
+	}
+	levelData, err := loadLevelData(cache, chartSource, chart)
+	if err != nil {
+		return exitCode(err)
+	}
+	scoreData, err := calculateScore(chart, levelData, &scoreFlags, skills, judgeTable)
+	if err != nil {
+		return exitCode(err)
+	}
+
+	rank, _ := rankTable.Rank(scoreData.Breakdown.Total, chart.Rating)
+	report.score(scoreData, rank)
+
+	if outputPath != "" {
+		report.stageStarted(stageTimeline, t("progress.timeline"))
+		err = pjsekaioverlay.WriteTimelineFile(scoreData, outputPath, pjsekaioverlay.PedOptions{
+			RankTable: rankTable,
+			LevelInfo: sonolus.LevelInfo{Rating: chart.Rating},
+		}, timelineFps)
+		if err != nil {
+			return fail(stageTimeline, err)
+		}
+
+		report.stageFinished(stageTimeline)
+		report.output("timeline", outputPath)
+	}
+
+	return 0
+}
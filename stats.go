@@ -0,0 +1,84 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sevenc-nanashi/pjsekai-overlay/pkg/pjsekaioverlay"
+)
+
+var STATS_FORMATS = map[string]bool{
+	"table": true,
+	"json":  true,
+	"csv":   true,
+}
+
+func statsMain(args []string) int {
+	flagSet := flag.NewFlagSet("stats", flag.ExitOnError)
+
+	var scoreFlags scoreFlags
+	scoreFlags.register(flagSet)
+
+	var format string
+	flagSet.StringVar(&format, "format", "table", t("flag.format"))
+
+	var configFlags configFlags
+	configFlags.register(flagSet)
+
+	var reportFlags reportFlags
+	reportFlags.register(flagSet)
+
+	flagSet.Usage = func() {
+		fmt.Println(t("usage.stats"))
+		flagSet.PrintDefaults()
+	}
+
+	flagSet.Parse(args)
+	reportFlags.apply()
+	if err := configFlags.apply(flagSet); err != nil {
+		return fail(stageOptions, err)
+	}
+	// JSONとCSVは標準出力に書くので、進捗は標準エラー出力に出す
+	if format != "table" {
+		report = &consoleReporter{writer: os.Stderr}
+	}
+	reportFlags.apply()
+
+	chartId := flagSet.Arg(0)
+	if chartId == "" {
+		flagSet.Usage()
+		return 2
+	}
+	if !STATS_FORMATS[format] {
+		return fail(stageOptions, pjsekaioverlay.Errorf("stats.unknownFormat", format))
+	}
+	skills, err := scoreFlags.skills()
+	if err != nil {
+		return fail(stageOptions, err)
+	}
+	judgeTable, err := scoreFlags.judgeTable()
+	if err != nil {
+		return fail(stageOptions, err)
+	}
+
+	cache := openCache()
+	chartSource, chart, err := fetchChart(cache, chartId)
+	if err != nil {
+		return exitCode(err)
+	}
+	levelData, err := loadLevelData(cache, chartSource, chart)
+	if err != nil {
+		return exitCode(err)
+	}
+	scoreData, err := calculateScore(chart, levelData, &scoreFlags, skills, judgeTable)
+	if err != nil {
+		return exitCode(err)
+	}
+
+	if err := report.stats(pjsekaioverlay.CalculateStats(chart, scoreData), format); err != nil {
+		return fail(stageScore, err)
+	}
+
+	return 0
+}
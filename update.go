@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/google/go-github/v57/github"
+	"github.com/sevenc-nanashi/pjsekai-overlay/pkg/pjsekaioverlay"
+)
+
+const (
+	releaseOwner      = "sevenc-nanashi"
+	releaseRepository = "pjsekai-overlay"
+	// 自動での確認の間隔
+	updateCheckInterval = 24 * time.Hour
+)
+
+// アップデートの確認に関するオプション。
+type updateFlags struct {
+	noUpdateCheck bool
+}
+
+func (flags *updateFlags) register(flagSet *flag.FlagSet) {
+	flagSet.BoolVar(&flags.noUpdateCheck, "no-update-check", false, t("flag.noUpdateCheck"))
+}
+
+func fetchLatestRelease() (*github.RepositoryRelease, error) {
+	githubClient := github.NewClient(&http.Client{Timeout: 10 * time.Second})
+	release, _, err := githubClient.Repositories.GetLatestRelease(context.Background(), releaseOwner, releaseRepository)
+	return release, err
+}
+
+// 現在のバージョンと最新のバージョンを比べる。どちらかが解析できない場合はfalse。
+func isNewerRelease(release *github.RepositoryRelease) (pjsekaioverlay.SemVer, pjsekaioverlay.SemVer, bool) {
+	currentVersion, err := pjsekaioverlay.ParseVersion(pjsekaioverlay.Version)
+	if err != nil {
+		return pjsekaioverlay.SemVer{}, pjsekaioverlay.SemVer{}, false
+	}
+	latestVersion, err := pjsekaioverlay.ParseVersion(release.GetTagName())
+	if err != nil {
+		return currentVersion, pjsekaioverlay.SemVer{}, false
+	}
+	return currentVersion, latestVersion, pjsekaioverlay.CompareVersions(latestVersion, currentVersion) > 0
+}
+
+func reportUpdateAvailable(release *github.RepositoryRelease, currentVersion pjsekaioverlay.SemVer, latestVersion pjsekaioverlay.SemVer) {
+	report.warning("updateAvailable", t("update.available", currentVersion, latestVersion), []string{
+		t("update.download", release.GetHTMLURL()),
+		t("update.command"),
+	})
+}
+
+// 1日に1回、新しいバージョンがあるかを確認する。オフラインの場合は何もしない。
+func checkUpdate(flags *updateFlags) {
+	if flags.noUpdateCheck || !pjsekaioverlay.ShouldCheckUpdate(updateCheckInterval) {
+		return
+	}
+	if !pjsekaioverlay.IsOnline("api.github.com", 2*time.Second) {
+		return
+	}
+	release, err := fetchLatestRelease()
+	if err != nil {
+		return
+	}
+	pjsekaioverlay.SaveUpdateCheck(time.Now())
+
+	if currentVersion, latestVersion, newer := isNewerRelease(release); newer {
+		reportUpdateAvailable(release, currentVersion, latestVersion)
+	}
+}
+
+func findReleaseAsset(release *github.RepositoryRelease, name string) *github.ReleaseAsset {
+	for _, asset := range release.Assets {
+		if asset.GetName() == name {
+			return asset
+		}
+	}
+	return nil
+}
+
+// 最新のリリースのzipをダウンロードし、exeとアセットを置き換える。
+func updateMain(args []string) int {
+	flagSet := flag.NewFlagSet("update", flag.ExitOnError)
+	var checkOnly, force bool
+	flagSet.BoolVar(&checkOnly, "check", false, t("flag.updateCheck"))
+	flagSet.BoolVar(&force, "force", false, t("flag.updateForce"))
+	var reportFlags reportFlags
+	reportFlags.register(flagSet)
+
+	flagSet.Usage = func() {
+		fmt.Println(t("usage.update"))
+		flagSet.PrintDefaults()
+	}
+	flagSet.Parse(args)
+	reportFlags.apply()
+
+	executablePath, err := os.Executable()
+	if err != nil {
+		return fail(stageUpdate, err)
+	}
+	installDir := filepath.Dir(executablePath)
+	pjsekaioverlay.CleanupUpdate(installDir)
+
+	report.stageStarted(stageUpdate, t("progress.checkUpdate"))
+	if !pjsekaioverlay.IsOnline("api.github.com", 5*time.Second) {
+		return fail(stageUpdate, pjsekaioverlay.NewError(pjsekaioverlay.ErrorNetwork, nil, "error.offline"))
+	}
+	release, err := fetchLatestRelease()
+	if err != nil {
+		return fail(stageUpdate, pjsekaioverlay.NewError(pjsekaioverlay.ErrorNetwork, err, "error.connectCause", err))
+	}
+	report.stageFinished(stageUpdate)
+	pjsekaioverlay.SaveUpdateCheck(time.Now())
+
+	currentVersion, latestVersion, newer := isNewerRelease(release)
+	if !newer && !force {
+		report.info(color.GreenString(t("update.upToDate", currentVersion)))
+		return 0
+	}
+	if checkOnly {
+		reportUpdateAvailable(release, currentVersion, latestVersion)
+		return 0
+	}
+
+	asset := findReleaseAsset(release, pjsekaioverlay.ReleaseAssetName)
+	if asset == nil {
+		return fail(stageUpdate, pjsekaioverlay.Errorf("error.updateAssetNotFound", pjsekaioverlay.ReleaseAssetName))
+	}
+
+	downloadDir, err := os.MkdirTemp("", "pjsekai-overlay-update-")
+	if err != nil {
+		return fail(stageUpdate, pjsekaioverlay.NewError(pjsekaioverlay.ErrorFilesystem, err, "error.createFile", err))
+	}
+	defer os.RemoveAll(downloadDir)
+
+	report.stageStarted(stageUpdate, t("progress.downloadUpdate", release.GetTagName()))
+	zipPath := filepath.Join(downloadDir, pjsekaioverlay.ReleaseAssetName)
+	checksum, err := pjsekaioverlay.DownloadFile("update", asset.GetBrowserDownloadURL(), zipPath)
+	if err != nil {
+		return fail(stageUpdate, err)
+	}
+	// 古いリリースにはハッシュのファイルが無いので、その場合はサイズとzipの中身だけを確かめる
+	expectedChecksum := ""
+	if checksumAsset := findReleaseAsset(release, pjsekaioverlay.ReleaseChecksumName); checksumAsset != nil {
+		checksumPath := filepath.Join(downloadDir, pjsekaioverlay.ReleaseChecksumName)
+		if _, err := pjsekaioverlay.DownloadFile("checksum", checksumAsset.GetBrowserDownloadURL(), checksumPath); err != nil {
+			return fail(stageUpdate, err)
+		}
+		expectedChecksum, err = pjsekaioverlay.FindChecksum(checksumPath, pjsekaioverlay.ReleaseAssetName)
+		if err != nil {
+			return fail(stageUpdate, err)
+		}
+	}
+	report.stageFinished(stageUpdate)
+
+	report.stageStarted(stageUpdate, t("progress.verifyUpdate"))
+	size := int64(-1)
+	if asset.Size != nil {
+		size = int64(asset.GetSize())
+	}
+	if err := pjsekaioverlay.VerifyUpdate(zipPath, size, checksum, expectedChecksum); err != nil {
+		return fail(stageUpdate, err)
+	}
+	report.stageFinished(stageUpdate)
+
+	report.stageStarted(stageUpdate, t("progress.applyUpdate"))
+	if err := pjsekaioverlay.ApplyUpdate(zipPath, installDir, filepath.Base(executablePath)); err != nil {
+		return fail(stageUpdate, err)
+	}
+	report.stageFinished(stageUpdate)
+	report.output("executable", executablePath)
+	report.info(color.GreenString(t("update.updated", latestVersion)))
+
+	return 0
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/sevenc-nanashi/pjsekai-overlay/pkg/pjsekaioverlay"
+)
+
+// weightsPathが空の場合はexeと同じ場所のweights.jsonを探す。
+func loadWeights(weightsPath string, engine string) (map[string]float64, error) {
+	if weightsPath == "" {
+		executablePath, err := os.Executable()
+		if err == nil {
+			defaultWeightsPath := filepath.Join(filepath.Dir(executablePath), "weights.json")
+			if _, err := os.Stat(defaultWeightsPath); err == nil {
+				weightsPath = defaultWeightsPath
+			}
+		}
+	}
+	weightConfig := pjsekaioverlay.WeightConfig{}
+	if weightsPath != "" {
+		var err error
+		weightConfig, err = pjsekaioverlay.LoadWeightConfig(weightsPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return weightConfig.WeightsFor(engine), nil
+}